@@ -2,30 +2,225 @@ package main
 
 import (
 	"context"
-	"database/sql"
+	"fmt"
 	"log"
 	"net/http"
 	"os"
 	"os/signal"
+	"strconv"
+	"strings"
 	"syscall"
 	"time"
 
 	"github.com/realworld/backend/internal/database"
 	"github.com/realworld/backend/internal/handlers"
 	"github.com/realworld/backend/internal/middleware"
+	"github.com/realworld/backend/internal/models"
+	"github.com/realworld/backend/internal/storage"
+	"github.com/realworld/backend/internal/utils"
+	"github.com/redis/go-redis/v9"
 
 	_ "github.com/mattn/go-sqlite3" // SQLite driver
 )
 
+// Build information, injected with -ldflags "-X main.version=... -X main.commit=... -X main.buildTime=..."
+var (
+	version   = "dev"
+	commit    = "unknown"
+	buildTime = "unknown"
+)
+
 func main() {
+	startTime := time.Now()
+
 	// Environment configuration
 	port := getEnv("PORT", "8080")
 	dbPath := getEnv("DB_PATH", "./data/realworld.db")
-	jwtSecret := getEnv("JWT_SECRET", "your-development-secret-change-in-production")
+	production := getEnv("ENV", "development") == "production"
+	jwtSecret := getEnv("JWT_SECRET", defaultJWTSecret)
+	utils.TokenClaims = utils.TokenClaimsConfig{
+		Issuer:       getEnv("JWT_ISSUER", utils.TokenClaims.Issuer),
+		Audience:     getEnv("JWT_AUDIENCE", ""),
+		AllowMissing: getEnvBool("JWT_ACCEPT_LEGACY_TOKENS", utils.TokenClaims.AllowMissing),
+	}
+	tokenSigning, err := utils.NewTokenSigning(getEnv("JWT_ALGORITHM", utils.SigningHS256), getEnv("JWT_PRIVATE_KEY_FILE", ""))
+	if err != nil {
+		log.Fatalf("Invalid configuration: JWT signing: %v", err)
+	}
+	utils.TokenSigning = tokenSigning
+	rateLimitBackend := getEnv("RATE_LIMIT_BACKEND", "memory")
+	redisURL := getEnv("REDIS_URL", "redis://localhost:6379/0")
+	maxConcurrency := getEnvInt("MAX_CONCURRENCY", 100)
+	availabilityRateLimit := getEnvInt("AVAILABILITY_RATE_LIMIT", 10)
+	twoFactorEnabled := getEnvBool("TWO_FACTOR_ENABLED", false)
+	twoFactorKey := getEnv("TWO_FACTOR_ENCRYPTION_KEY", jwtSecret)
+	storageBackend := getEnv("STORAGE_BACKEND", "local")
+	registrationEnabled := getEnvBool("REGISTRATION_ENABLED", true)
+	admins := make(map[string]bool)
+	for _, name := range strings.Split(getEnv("ADMIN_USERNAMES", ""), ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			admins[strings.ToLower(name)] = true
+		}
+	}
+	articleCooldownSeconds := getEnvInt("ARTICLE_CREATE_COOLDOWN_SECONDS", 5)
+	if articleCooldownSeconds < 0 {
+		log.Fatalf("Invalid configuration: ARTICLE_CREATE_COOLDOWN_SECONDS must not be negative, got %d", articleCooldownSeconds)
+	}
+	maxFollowsPerDay := getEnvInt("MAX_FOLLOWS_PER_DAY", 500)
+	if maxFollowsPerDay < 0 {
+		log.Fatalf("Invalid configuration: MAX_FOLLOWS_PER_DAY must not be negative, got %d", maxFollowsPerDay)
+	}
+	var lenientSelfFollow bool
+	switch mode := getEnv("SELF_FOLLOW_MODE", "strict"); mode {
+	case "strict":
+	case "lenient":
+		lenientSelfFollow = true
+	default:
+		log.Fatalf("Invalid configuration: SELF_FOLLOW_MODE must be strict or lenient, got %q", mode)
+	}
+	cloneAnyArticle := getEnvBool("ALLOW_CLONE_ANY_ARTICLE", false)
+	allowSelfCommentLikes := getEnvBool("ALLOW_SELF_COMMENT_LIKES", true)
+	models.MaxCommentLinks = getEnvInt("MAX_COMMENT_LINKS", 0)
+	if models.MaxCommentLinks < 0 {
+		log.Fatalf("Invalid configuration: MAX_COMMENT_LINKS must not be negative, got %d", models.MaxCommentLinks)
+	}
+	maxCommentsPerArticle := getEnvInt("MAX_COMMENTS_PER_ARTICLE", 10000)
+	if maxCommentsPerArticle < 0 {
+		log.Fatalf("Invalid configuration: MAX_COMMENTS_PER_ARTICLE must not be negative, got %d", maxCommentsPerArticle)
+	}
+	maxPageOffset := getEnvInt("MAX_PAGE_OFFSET", 10000)
+	if maxPageOffset < 0 {
+		log.Fatalf("Invalid configuration: MAX_PAGE_OFFSET must not be negative, got %d", maxPageOffset)
+	}
+	slowQueryMS := getEnvInt("SLOW_QUERY_MS", 0)
+	slowQueryLogArgs := getEnvBool("SLOW_QUERY_LOG_ARGS", false)
+	statsCacheSeconds := getEnvInt("STATS_CACHE_SECONDS", 30)
+	tagsCacheSeconds := getEnvInt("TAGS_CACHE_SECONDS", 300)
+	articleListCacheSeconds := getEnvInt("ARTICLE_LIST_CACHE_SECONDS", 5)
+	if articleListCacheSeconds < 0 {
+		log.Fatalf("Invalid configuration: ARTICLE_LIST_CACHE_SECONDS must not be negative, got %d", articleListCacheSeconds)
+	}
+	tagSuggestMinPrefix := getEnvInt("TAG_SUGGEST_MIN_PREFIX", 2)
+	tagSuggestMaxResults := getEnvInt("TAG_SUGGEST_MAX_RESULTS", 10)
+	if tagSuggestMaxResults < 1 || tagSuggestMaxResults > 100 {
+		log.Fatalf("Invalid configuration: TAG_SUGGEST_MAX_RESULTS must be between 1 and 100, got %d", tagSuggestMaxResults)
+	}
+	trendingDays := getEnvInt("TRENDING_TAGS_DAYS", 7)
+	if trendingDays < 1 {
+		log.Fatalf("Invalid configuration: TRENDING_TAGS_DAYS must be positive, got %d", trendingDays)
+	}
+	trendingMinFavorites := getEnvInt("TRENDING_TAGS_MIN_FAVORITES", 1)
+	trendingCacheSeconds := getEnvInt("TRENDING_TAGS_CACHE_SECONDS", 60)
+	defaultArticleSort := getEnv("DEFAULT_ARTICLE_SORT", models.ArticleSortNewest)
+	if !models.ValidArticleSort(defaultArticleSort) {
+		log.Fatalf("Invalid configuration: DEFAULT_ARTICLE_SORT must be one of %s, got %q", strings.Join(models.ArticleSorts, ", "), defaultArticleSort)
+	}
+	recommendationsPerTag := getEnvInt("RECOMMENDATIONS_PER_TAG", 0)
+	if recommendationsPerTag < 0 {
+		log.Fatalf("Invalid configuration: RECOMMENDATIONS_PER_TAG must not be negative, got %d", recommendationsPerTag)
+	}
+	emptyListSuggestions := getEnvInt("EMPTY_LIST_SUGGESTIONS", 5)
+	if emptyListSuggestions < 0 || emptyListSuggestions > 20 {
+		log.Fatalf("Invalid configuration: EMPTY_LIST_SUGGESTIONS must be between 0 and 20, got %d", emptyListSuggestions)
+	}
+	tokenCutoffCacheSeconds := getEnvInt("TOKEN_CUTOFF_CACHE_SECONDS", 30)
+	debugBodyLog := getEnvBool("DEBUG_BODY_LOG", false)
+	models.PrettyJSON = getEnvBool("JSON_PRETTY", false)
+	models.RequireHTTPSImages = getEnvBool("REQUIRE_HTTPS_IMAGES", false)
+	switch mode := getEnv("EMAIL_VALIDATION", "simple"); mode {
+	case "simple":
+	case "strict":
+		models.StrictEmailValidation = true
+	default:
+		log.Fatalf("Invalid configuration: EMAIL_VALIDATION must be simple or strict, got %q", mode)
+	}
+	models.DefaultAvatarURL = getEnv("DEFAULT_AVATAR_URL", "")
+	models.AllowedImageHosts, err = parseHostList(getEnv("ALLOWED_IMAGE_HOSTS", ""))
+	if err != nil {
+		log.Fatalf("Invalid configuration: ALLOWED_IMAGE_HOSTS: %v", err)
+	}
+	models.ExcerptLength = getEnvInt("ARTICLE_EXCERPT_LENGTH", models.ExcerptLength)
+	if models.ExcerptLength < 0 {
+		log.Fatalf("Invalid configuration: ARTICLE_EXCERPT_LENGTH must not be negative, got %d", models.ExcerptLength)
+	}
+	models.MaxTitleLength = getEnvInt("MAX_TITLE_LENGTH", models.MaxTitleLength)
+	if models.MaxTitleLength < 1 || models.MaxTitleLength > 255 {
+		log.Fatalf("Invalid configuration: MAX_TITLE_LENGTH must be between 1 and 255, got %d", models.MaxTitleLength)
+	}
+	models.MaxDescriptionLength = getEnvInt("MAX_DESCRIPTION_LENGTH", models.MaxDescriptionLength)
+	if models.MaxDescriptionLength < 1 {
+		log.Fatalf("Invalid configuration: MAX_DESCRIPTION_LENGTH must be positive, got %d", models.MaxDescriptionLength)
+	}
+	models.MaxArticleBodyLength = getEnvInt("ARTICLE_BODY_MAX_LENGTH", models.MaxArticleBodyLength)
+	if models.MaxArticleBodyLength < 1 {
+		log.Fatalf("Invalid configuration: ARTICLE_BODY_MAX_LENGTH must be positive, got %d", models.MaxArticleBodyLength)
+	}
+	models.Usernames = models.UsernamePolicy{
+		MinLength:          getEnvInt("USERNAME_MIN_LENGTH", models.UsernameMinLengthLimit),
+		MaxLength:          getEnvInt("USERNAME_MAX_LENGTH", models.UsernameMaxLengthLimit),
+		RequireLetterStart: getEnvBool("USERNAME_REQUIRE_LETTER_START", false),
+	}
+	if err := models.Usernames.Check(); err != nil {
+		log.Fatalf("Invalid username policy: %v", err)
+	}
+	debugBodyLogMaxBytes := getEnvInt("DEBUG_BODY_LOG_MAX_BYTES", 2048)
+	logSampleRate := getEnvFloat("LOG_SAMPLE_RATE", 1.0)
+	if !(logSampleRate >= 0 && logSampleRate <= 1) {
+		log.Fatalf("Invalid configuration: LOG_SAMPLE_RATE must be between 0.0 and 1.0, got %v", logSampleRate)
+	}
+	shutdownTimeout, err := time.ParseDuration(getEnv("SHUTDOWN_TIMEOUT", "5s"))
+	if err != nil || shutdownTimeout <= 0 {
+		log.Fatalf("Invalid configuration: SHUTDOWN_TIMEOUT must be a positive duration such as 30s, got %q", os.Getenv("SHUTDOWN_TIMEOUT"))
+	}
+	var publicBaseURL string
+	if value := getEnv("PUBLIC_BASE_URL", ""); value != "" {
+		publicBaseURL, err = handlers.ParsePublicBaseURL(value)
+		if err != nil {
+			log.Fatalf("Invalid configuration: PUBLIC_BASE_URL %v", err)
+		}
+	}
+	avatarMaxBytes := getEnvInt("AVATAR_MAX_BYTES", 2*1024*1024)
+	avatarMaxDimension := getEnvInt("AVATAR_MAX_DIMENSION", 1024)
+
+	if value := getEnv("TRUSTED_PROXIES", ""); value != "" {
+		trustedProxies, err := middleware.ParseTrustedProxies(value)
+		if err != nil {
+			log.Fatalf("Invalid configuration: TRUSTED_PROXIES: %v", err)
+		}
+		middleware.TrustedProxies = trustedProxies
+	}
+	httpsRedirect := getEnvBool("HTTPS_REDIRECT", false)
+	if httpsRedirect && middleware.TrustedProxies == nil {
+		log.Fatalf("Invalid configuration: HTTPS_REDIRECT requires TRUSTED_PROXIES")
+	}
+	geoBlock, err := geoBlockOptionsFromEnv()
+	if err != nil {
+		log.Fatalf("Invalid configuration: %v", err)
+	}
+	securityHeaders := securityHeadersOptionsFromEnv()
+	if err := securityHeaders.Validate(); err != nil {
+		log.Fatalf("Invalid configuration: %v", err)
+	}
+
+	slugStrategy, err := utils.ParseSlugStrategy(getEnv("SLUG_STRATEGY", string(utils.SlugStrategyTitle)))
+	if err != nil {
+		log.Fatal("Invalid configuration: ", err)
+	}
 
 	// Initialize logger
 	logger := log.New(os.Stdout, "realworld-api: ", log.LstdFlags)
 
+	// The secret signs HS256 tokens and is the fallback 2FA encryption key;
+	// a known or short one lets anyone forge tokens
+	secretInUse := tokenSigning.Algorithm == utils.SigningHS256 ||
+		(twoFactorEnabled && os.Getenv("TWO_FACTOR_ENCRYPTION_KEY") == "")
+	if problem := jwtSecretProblem(jwtSecret); secretInUse && problem != "" {
+		if production {
+			logger.Fatalf("Refusing to start in production: JWT_SECRET %s; set a random secret of at least %d characters", problem, minJWTSecretLength)
+		}
+		logger.Printf("WARNING: JWT_SECRET %s; this is only acceptable in development", problem)
+	}
+
 	// Initialize database
 	db, err := database.New(dbPath)
 	if err != nil {
@@ -35,22 +230,132 @@ func main() {
 
 	logger.Println("Database initialized successfully")
 
+	if debugBodyLog {
+		logger.Println("WARNING: DEBUG_BODY_LOG is enabled; request and response bodies will be logged")
+	}
+
+	// Initialize image storage
+	imageStorage, err := newStorage(storageBackend)
+	if err != nil {
+		logger.Fatal("Failed to initialize storage:", err)
+	}
+
+	logger.Printf("Storing images with %s backend", storageBackend)
+
 	// Initialize handlers
 	h := &handlers.Handler{
-		DB:        db.DB,
-		JWTSecret: jwtSecret,
-		Logger:    logger,
+		DB:           db.DB,
+		JWTSecret:    jwtSecret,
+		Logger:       logger,
+		SlugStrategy: slugStrategy,
+
+		TwoFactorEnabled: twoFactorEnabled,
+		TwoFactorKey:     utils.DeriveKey(twoFactorKey),
+
+		Storage:            imageStorage,
+		AvatarMaxBytes:     int64(avatarMaxBytes),
+		AvatarMaxDimension: avatarMaxDimension,
+		PublicBaseURL:      publicBaseURL,
+
+		RegistrationEnabled:   registrationEnabled,
+		Admins:                admins,
+		MaxFollowsPerDay:      maxFollowsPerDay,
+		LenientSelfFollow:     lenientSelfFollow,
+		ArticleCooldown:       time.Duration(articleCooldownSeconds) * time.Second,
+		CloneAnyArticle:       cloneAnyArticle,
+		AllowSelfCommentLikes: allowSelfCommentLikes,
+		MaxCommentsPerArticle: maxCommentsPerArticle,
+		MaxPageOffset:         maxPageOffset,
+
+		Build: handlers.BuildInfo{
+			Version:   version,
+			Commit:    commit,
+			BuildTime: buildTime,
+		},
+		StartTime: startTime,
+
+		SlowQueryThreshold: time.Duration(slowQueryMS) * time.Millisecond,
+		SlowQueryLogArgs:   slowQueryLogArgs,
+
+		StatsCacheTTL: time.Duration(statsCacheSeconds) * time.Second,
+		TagsCacheTTL:  time.Duration(tagsCacheSeconds) * time.Second,
+
+		ArticleListCacheTTL: time.Duration(articleListCacheSeconds) * time.Second,
+
+		TagSuggestMinPrefix:  tagSuggestMinPrefix,
+		TagSuggestMaxResults: tagSuggestMaxResults,
+
+		TrendingDays:         trendingDays,
+		TrendingMinFavorites: trendingMinFavorites,
+		TrendingCacheTTL:     time.Duration(trendingCacheSeconds) * time.Second,
+
+		EmptyListSuggestions: emptyListSuggestions,
+		DefaultArticleSort:   defaultArticleSort,
+
+		RecommendationsPerTag: recommendationsPerTag,
+	}
+	h.TokenCutoffs = middleware.NewTokenCutoffCache(h.TokenValidAfter, time.Duration(tokenCutoffCacheSeconds)*time.Second)
+	h.Auth = middleware.AuthConfig{
+		Secret:   jwtSecret,
+		Cutoffs:  h.TokenCutoffs,
+		APIKeys:  h,
+		Sessions: h,
+	}
+	if getEnvBool("LOG_AUTH_FAILURES", true) {
+		h.Auth.FailureLog = logger
+	}
+
+	// Initialize rate limiters: a general one for all requests, and a tight
+	// one for the availability check so it can't be used to enumerate users
+	var redisClient *redis.Client
+	if rateLimitBackend == "redis" {
+		redisClient, err = newRedisClient(redisURL)
+		if err != nil {
+			logger.Fatal("Failed to initialize rate limiter:", err)
+		}
+	}
+
+	limiter, err := newRateLimiter(rateLimitBackend, redisClient, "api", 100, time.Minute)
+	if err != nil {
+		logger.Fatal("Failed to initialize rate limiter:", err)
+	}
+
+	availabilityLimiter, err := newRateLimiter(rateLimitBackend, redisClient, "availability", availabilityRateLimit, time.Minute)
+	if err != nil {
+		logger.Fatal("Failed to initialize rate limiter:", err)
+	}
+
+	logger.Printf("Rate limiting with %s backend", rateLimitBackend)
+
+	if geoBlock.Ranges != nil {
+		logger.Printf("Geoblocking with %d IP ranges", geoBlock.Ranges.Len())
+		if middleware.TrustedProxies == nil {
+			logger.Println("WARNING: geoblocking without TRUSTED_PROXIES uses the connecting address, so clients behind a proxy all look like the proxy")
+		}
 	}
 
 	// Setup routes
-	mux := setupRoutes(h)
+	mux := setupRoutes(h, availabilityLimiter)
 
-	// Setup middleware chain
+	// Setup middleware chain; the in-flight counter is outermost so shutdown
+	// sees every request
+	var inFlight middleware.InFlight
 	handler := middleware.Chain(mux,
-		middleware.CORS(),
-		middleware.Logging(logger),
+		inFlight.Middleware(),
+		middleware.CORS(corsOptionsFromEnv(logger)),
+		middleware.SecurityHeaders(securityHeaders),
+		middleware.Logging(logger, middleware.LoggingOptions{
+			LogBodies:    debugBodyLog,
+			MaxBodyBytes: debugBodyLogMaxBytes,
+			SampleRate:   logSampleRate,
+		}),
 		middleware.Recovery(logger),
-		middleware.RateLimit(),
+		middleware.HTTPSRedirect(httpsRedirect),
+		middleware.GeoBlock(geoBlock),
+		middleware.TrailingSlash(),
+		middleware.MaxConcurrency(maxConcurrency),
+		middleware.RateLimit(limiter),
+		middleware.APIVersion(),
 	)
 
 	// HTTP server configuration
@@ -65,7 +370,11 @@ func main() {
 	// Start server in a goroutine
 	go func() {
 		logger.Printf("Server starting on port %s", port)
-		logger.Printf("API available at: http://localhost:%s/api", port)
+		if publicBaseURL != "" {
+			logger.Printf("API available at: %s/api", publicBaseURL)
+		} else {
+			logger.Printf("API available at: http://localhost:%s/api", port)
+		}
 		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
 			logger.Fatal("Failed to start server:", err)
 		}
@@ -76,65 +385,378 @@ func main() {
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 	<-quit
 
-	logger.Println("Shutting down server...")
+	logger.Printf("Shutting down server with %d requests in flight (timeout %s)...", inFlight.Count(), shutdownTimeout)
+	shutdownStart := time.Now()
 
 	// Create shutdown context with timeout
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
 	defer cancel()
 
 	// Shutdown server
 	if err := server.Shutdown(ctx); err != nil {
-		logger.Fatal("Server forced to shutdown:", err)
+		logger.Fatalf("Server forced to shutdown after %s with %d requests in flight: %v", time.Since(shutdownStart).Round(time.Millisecond), inFlight.Count(), err)
+	}
+
+	// Stop the in-memory limiters' janitors
+	for _, l := range []middleware.RateLimiter{limiter, availabilityLimiter} {
+		if closer, ok := l.(interface{ Close() }); ok {
+			closer.Close()
+		}
 	}
 
-	logger.Println("Server exited")
+	logger.Printf("Server exited after %s", time.Since(shutdownStart).Round(time.Millisecond))
 }
 
-func setupRoutes(h *handlers.Handler) *http.ServeMux {
+func setupRoutes(h *handlers.Handler, availabilityLimiter middleware.RateLimiter) *http.ServeMux {
 	mux := http.NewServeMux()
 
 	// Health check endpoint
 	mux.HandleFunc("GET /health", h.Health)
 
+	// Aggregate site totals - public
+	mux.HandleFunc("GET /api/stats", h.GetStats)
+
+	// API description - public
+	mux.HandleFunc("GET /api/openapi.json", h.OpenAPI)
+
 	// Authentication routes - public
 	mux.HandleFunc("POST /api/users/login", h.Login)
 	mux.HandleFunc("POST /api/users", h.Register)
+	if h.RegistrationEnabled {
+		mux.Handle("GET /api/users/availability", middleware.RateLimit(availabilityLimiter)(http.HandlerFunc(h.CheckAvailability)))
+	}
 
 	// User routes - protected
-	mux.Handle("GET /api/user", middleware.Auth(h.JWTSecret)(http.HandlerFunc(h.GetCurrentUser)))
-	mux.Handle("PUT /api/user", middleware.Auth(h.JWTSecret)(http.HandlerFunc(h.UpdateUser)))
+	mux.Handle("GET /api/user", middleware.Auth(h.Auth)(http.HandlerFunc(h.GetCurrentUser)))
+	mux.Handle("PUT /api/user", middleware.Auth(h.Auth)(http.HandlerFunc(h.UpdateUser)))
+	mux.Handle("GET /api/user/favorites", middleware.Auth(h.Auth)(http.HandlerFunc(h.GetUserFavorites)))
+	mux.Handle("GET /api/user/feed/unread", middleware.Auth(h.Auth)(http.HandlerFunc(h.GetUnreadFeedCount)))
+	mux.Handle("POST /api/user/refresh", middleware.Auth(h.Auth)(http.HandlerFunc(h.RefreshUserToken)))
+	mux.Handle("GET /api/user/security-log", middleware.Auth(h.Auth)(http.HandlerFunc(h.GetSecurityLog)))
+
+	mux.Handle("POST /api/user/avatar", middleware.Auth(h.Auth)(http.HandlerFunc(h.UploadAvatar)))
+
+	// API key routes - protected; keys can't be managed with an API key
+	mux.Handle("GET /api/user/api-keys", middleware.Auth(h.Auth)(http.HandlerFunc(h.ListAPIKeys)))
+	mux.Handle("POST /api/user/api-keys", middleware.Auth(h.Auth)(http.HandlerFunc(h.CreateAPIKey)))
+	mux.Handle("DELETE /api/user/api-keys/{id}", middleware.Auth(h.Auth)(http.HandlerFunc(h.RevokeAPIKey)))
+	mux.Handle("GET /api/user/sessions", middleware.Auth(h.Auth)(http.HandlerFunc(h.ListSessions)))
+	mux.Handle("DELETE /api/user/sessions/{id}", middleware.Auth(h.Auth)(http.HandlerFunc(h.RevokeSession)))
+
+	// Two-factor authentication routes - protected, opt-in
+	if h.TwoFactorEnabled {
+		mux.Handle("POST /api/user/2fa/enroll", middleware.Auth(h.Auth)(http.HandlerFunc(h.EnrollTwoFactor)))
+		mux.Handle("POST /api/user/2fa/confirm", middleware.Auth(h.Auth)(http.HandlerFunc(h.ConfirmTwoFactor)))
+	}
 
 	// Profile routes
-	mux.HandleFunc("GET /api/profiles/{username}", h.GetProfile)
-	mux.Handle("POST /api/profiles/{username}/follow", middleware.Auth(h.JWTSecret)(http.HandlerFunc(h.FollowUser)))
-	mux.Handle("DELETE /api/profiles/{username}/follow", middleware.Auth(h.JWTSecret)(http.HandlerFunc(h.UnfollowUser)))
+	mux.Handle("GET /api/profiles", middleware.OptionalAuth(h.Auth)(http.HandlerFunc(h.GetProfiles)))
+	mux.Handle("GET /api/profiles/{username}", middleware.OptionalAuth(h.Auth)(http.HandlerFunc(h.GetProfile)))
+	mux.Handle("GET /api/profiles/{username}/articles", middleware.OptionalAuth(h.Auth)(http.HandlerFunc(h.GetProfileArticles)))
+	mux.Handle("POST /api/profiles/{username}/follow", middleware.Auth(h.Auth)(http.HandlerFunc(h.FollowUser)))
+	mux.Handle("DELETE /api/profiles/{username}/follow", middleware.Auth(h.Auth)(http.HandlerFunc(h.UnfollowUser)))
+	mux.Handle("POST /api/profiles/{username}/block", middleware.Auth(h.Auth)(http.HandlerFunc(h.BlockUser)))
+	mux.Handle("DELETE /api/profiles/{username}/block", middleware.Auth(h.Auth)(http.HandlerFunc(h.UnblockUser)))
 
 	// Article routes
-	mux.HandleFunc("GET /api/articles", h.ListArticles)
-	mux.HandleFunc("GET /api/articles/{slug}", h.GetArticle)
-	mux.Handle("GET /api/articles/feed", middleware.Auth(h.JWTSecret)(http.HandlerFunc(h.GetFeed)))
-	mux.Handle("POST /api/articles", middleware.Auth(h.JWTSecret)(http.HandlerFunc(h.CreateArticle)))
-	mux.Handle("PUT /api/articles/{slug}", middleware.Auth(h.JWTSecret)(http.HandlerFunc(h.UpdateArticle)))
-	mux.Handle("DELETE /api/articles/{slug}", middleware.Auth(h.JWTSecret)(http.HandlerFunc(h.DeleteArticle)))
+	mux.Handle("GET /api/articles", middleware.OptionalAuth(h.Auth)(http.HandlerFunc(h.ListArticles)))
+	mux.Handle("GET /api/articles/{slug}", middleware.OptionalAuth(h.Auth)(http.HandlerFunc(h.GetArticle)))
+	mux.Handle("GET /api/articles/count", middleware.OptionalAuth(h.Auth)(http.HandlerFunc(h.CountArticles)))
+	mux.Handle("GET /api/articles/feed", middleware.Auth(h.Auth)(http.HandlerFunc(h.GetFeed)))
+	mux.Handle("POST /api/articles", middleware.Auth(h.Auth)(http.HandlerFunc(h.CreateArticle)))
+	mux.Handle("GET /api/articles/favorited-status", middleware.Auth(h.Auth)(http.HandlerFunc(h.GetFavoritedStatus)))
+	mux.Handle("POST /api/articles/import", middleware.Auth(h.Auth)(http.HandlerFunc(h.ImportArticles)))
+	mux.Handle("PUT /api/articles/{slug}", middleware.Auth(h.Auth)(http.HandlerFunc(h.UpdateArticle)))
+	mux.Handle("PATCH /api/articles/{slug}", middleware.Auth(h.Auth)(http.HandlerFunc(h.PatchArticle)))
+	mux.Handle("DELETE /api/articles/{slug}", middleware.Auth(h.Auth)(http.HandlerFunc(h.DeleteArticle)))
 
 	// Favorite routes
-	mux.Handle("POST /api/articles/{slug}/favorite", middleware.Auth(h.JWTSecret)(http.HandlerFunc(h.FavoriteArticle)))
-	mux.Handle("DELETE /api/articles/{slug}/favorite", middleware.Auth(h.JWTSecret)(http.HandlerFunc(h.UnfavoriteArticle)))
+	mux.Handle("POST /api/articles/{slug}/clone", middleware.Auth(h.Auth)(http.HandlerFunc(h.CloneArticle)))
+	mux.Handle("POST /api/articles/{slug}/favorite", middleware.Auth(h.Auth)(http.HandlerFunc(h.FavoriteArticle)))
+	mux.Handle("DELETE /api/articles/{slug}/favorite", middleware.Auth(h.Auth)(http.HandlerFunc(h.UnfavoriteArticle)))
+	mux.Handle("PUT /api/articles/{slug}/favorite", middleware.Auth(h.Auth)(http.HandlerFunc(h.SetFavorite)))
+	mux.Handle("POST /api/articles/{slug}/pin", middleware.Auth(h.Auth)(http.HandlerFunc(h.PinArticle)))
+	mux.Handle("DELETE /api/articles/{slug}/pin", middleware.Auth(h.Auth)(http.HandlerFunc(h.UnpinArticle)))
+	mux.Handle("POST /api/articles/{slug}/transfer", middleware.Auth(h.Auth)(http.HandlerFunc(h.TransferArticle)))
 
 	// Comment routes
-	mux.HandleFunc("GET /api/articles/{slug}/comments", h.GetComments)
-	mux.Handle("POST /api/articles/{slug}/comments", middleware.Auth(h.JWTSecret)(http.HandlerFunc(h.CreateComment)))
-	mux.Handle("DELETE /api/articles/{slug}/comments/{id}", middleware.Auth(h.JWTSecret)(http.HandlerFunc(h.DeleteComment)))
+	mux.Handle("GET /api/articles/{slug}/comments", middleware.OptionalAuth(h.Auth)(http.HandlerFunc(h.GetComments)))
+	mux.Handle("POST /api/articles/{slug}/comments", middleware.Auth(h.Auth)(http.HandlerFunc(h.CreateComment)))
+	mux.Handle("DELETE /api/articles/{slug}/comments", middleware.Auth(h.Auth)(http.HandlerFunc(h.DeleteComments)))
+	mux.Handle("DELETE /api/articles/{slug}/comments/{id}", middleware.Auth(h.Auth)(http.HandlerFunc(h.DeleteComment)))
+	mux.Handle("POST /api/articles/{slug}/comments/{id}/like", middleware.Auth(h.Auth)(http.HandlerFunc(h.LikeComment)))
+	mux.Handle("DELETE /api/articles/{slug}/comments/{id}/like", middleware.Auth(h.Auth)(http.HandlerFunc(h.UnlikeComment)))
+
+	// Admin routes
+	admin := func(next http.HandlerFunc) http.Handler {
+		return middleware.Auth(h.Auth)(middleware.RequireAdmin(h.IsAdmin)(next))
+	}
+	mux.Handle("POST /api/admin/invites", admin(h.CreateInvites))
+	mux.Handle("GET /api/admin/invites", admin(h.ListInvites))
+	// Comment authors may purge their own comments here too, so the handler
+	// does its own permission check
+	mux.Handle("DELETE /api/admin/comments/{id}", middleware.Auth(h.Auth)(http.HandlerFunc(h.PurgeComment)))
+
+	// Image routes
+	mux.HandleFunc("GET /api/images/{id}", h.GetImage)
 
 	// Tag routes
 	mux.HandleFunc("GET /api/tags", h.GetTags)
+	mux.HandleFunc("GET /api/tags/trending", h.GetTrendingTags)
+	mux.HandleFunc("GET /api/tags/suggest", h.SuggestTags)
+	mux.Handle("GET /api/tags/{name}/articles", middleware.OptionalAuth(h.Auth)(http.HandlerFunc(h.GetTagArticles)))
 
 	return mux
 }
 
+// newStorage builds the image storage for the configured backend
+func newStorage(backend string) (storage.Storage, error) {
+	switch backend {
+	case "local":
+		return storage.NewLocal(getEnv("UPLOAD_DIR", "./data/uploads"), "/api/images")
+	case "s3":
+		return storage.NewS3(storage.S3Config{
+			Endpoint:        getEnv("S3_ENDPOINT", "https://s3.amazonaws.com"),
+			Region:          getEnv("S3_REGION", "us-east-1"),
+			Bucket:          os.Getenv("S3_BUCKET"),
+			AccessKeyID:     os.Getenv("S3_ACCESS_KEY_ID"),
+			SecretAccessKey: os.Getenv("S3_SECRET_ACCESS_KEY"),
+			PublicURL:       os.Getenv("S3_PUBLIC_URL"),
+		})
+	default:
+		return nil, fmt.Errorf("unknown STORAGE_BACKEND %q (expected local or s3)", backend)
+	}
+}
+
+// corsOptionsFromEnv reads CORS settings, keeping the default for any value
+// that is unset or invalid
+func corsOptionsFromEnv(logger *log.Logger) middleware.CORSOptions {
+	opts := middleware.DefaultCORSOptions()
+
+	headerLists := []struct {
+		key  string
+		dest *[]string
+	}{
+		{"CORS_ALLOW_HEADERS", &opts.AllowHeaders},
+		{"CORS_EXPOSE_HEADERS", &opts.ExposeHeaders},
+	}
+	for _, list := range headerLists {
+		value := os.Getenv(list.key)
+		if value == "" {
+			continue
+		}
+		headers, err := middleware.ParseHeaderList(value)
+		if err != nil {
+			logger.Printf("Ignoring %s: %v", list.key, err)
+			continue
+		}
+		*list.dest = headers
+	}
+
+	if value := os.Getenv("CORS_ALLOW_METHODS"); value != "" {
+		methods, err := middleware.ParseMethodList(value)
+		if err != nil {
+			logger.Printf("Ignoring CORS_ALLOW_METHODS: %v", err)
+		} else {
+			opts.AllowMethods = methods
+		}
+	}
+
+	if value := os.Getenv("CORS_ALLOW_ORIGINS"); value != "" {
+		origins, err := middleware.ParseOriginList(value)
+		if err != nil {
+			logger.Printf("Ignoring CORS_ALLOW_ORIGINS: %v", err)
+		} else {
+			opts.AllowOrigins = origins
+		}
+	}
+
+	// Credentials can't be combined with "Access-Control-Allow-Origin: *"
+	opts.AllowCredentials = getEnvBool("CORS_ALLOW_CREDENTIALS", false)
+	if opts.AllowCredentials && len(opts.AllowOrigins) == 0 {
+		logger.Printf("Ignoring CORS_ALLOW_CREDENTIALS: it requires CORS_ALLOW_ORIGINS")
+		opts.AllowCredentials = false
+	}
+
+	if value := os.Getenv("CORS_MAX_AGE"); value != "" {
+		maxAge, err := strconv.Atoi(value)
+		if err != nil || maxAge < 0 {
+			logger.Printf("Ignoring CORS_MAX_AGE: %q is not a non-negative number of seconds", value)
+		} else {
+			opts.MaxAge = maxAge
+		}
+	}
+
+	return opts
+}
+
+// securityHeadersOptionsFromEnv reads the SECURITY_* settings over the
+// defaults; SECURITY_REFERRER_POLICY=off omits the header
+func securityHeadersOptionsFromEnv() middleware.SecurityHeadersOptions {
+	opts := middleware.DefaultSecurityHeadersOptions()
+	opts.NoSniff = getEnvBool("SECURITY_NOSNIFF", opts.NoSniff)
+	opts.FrameDeny = getEnvBool("SECURITY_FRAME_DENY", opts.FrameDeny)
+	opts.ReferrerPolicy = strings.ToLower(getEnv("SECURITY_REFERRER_POLICY", opts.ReferrerPolicy))
+	if opts.ReferrerPolicy == "off" {
+		opts.ReferrerPolicy = ""
+	}
+	opts.HSTSMaxAge = getEnvInt("SECURITY_HSTS_MAX_AGE", opts.HSTSMaxAge)
+	opts.HSTSIncludeSubdomains = getEnvBool("SECURITY_HSTS_INCLUDE_SUBDOMAINS", opts.HSTSIncludeSubdomains)
+	return opts
+}
+
+// geoBlockOptionsFromEnv reads the geoblocking settings; blocking stays
+// disabled unless GEOBLOCK_RANGES_FILE is set
+func geoBlockOptionsFromEnv() (middleware.GeoBlockOptions, error) {
+	opts := middleware.GeoBlockOptions{
+		Allow:        parseCountryList(os.Getenv("GEOBLOCK_ALLOW")),
+		Deny:         parseCountryList(os.Getenv("GEOBLOCK_DENY")),
+		BlockUnknown: getEnvBool("GEOBLOCK_BLOCK_UNKNOWN", false),
+	}
+
+	rangesFile := os.Getenv("GEOBLOCK_RANGES_FILE")
+	if rangesFile == "" {
+		if len(opts.Allow) > 0 || len(opts.Deny) > 0 {
+			return opts, fmt.Errorf("GEOBLOCK_ALLOW and GEOBLOCK_DENY require GEOBLOCK_RANGES_FILE")
+		}
+		return opts, nil
+	}
+	if len(opts.Allow) == 0 && len(opts.Deny) == 0 {
+		return opts, fmt.Errorf("GEOBLOCK_RANGES_FILE requires GEOBLOCK_ALLOW or GEOBLOCK_DENY")
+	}
+
+	f, err := os.Open(rangesFile)
+	if err != nil {
+		return opts, fmt.Errorf("GEOBLOCK_RANGES_FILE: %w", err)
+	}
+	defer f.Close()
+
+	opts.Ranges, err = middleware.LoadGeoRanges(f)
+	if err != nil {
+		return opts, fmt.Errorf("GEOBLOCK_RANGES_FILE: %w", err)
+	}
+	return opts, nil
+}
+
+// parseCountryList parses comma-separated country codes into an upper-case set
+func parseCountryList(value string) map[string]bool {
+	countries := make(map[string]bool)
+	for _, code := range strings.Split(value, ",") {
+		if code = strings.TrimSpace(code); code != "" {
+			countries[strings.ToUpper(code)] = true
+		}
+	}
+	return countries
+}
+
+// parseHostList parses comma-separated host names, each optionally prefixed
+// with "*." to cover subdomains, into lower case
+func parseHostList(value string) ([]string, error) {
+	var hosts []string
+	for _, host := range strings.Split(value, ",") {
+		host = strings.ToLower(strings.TrimSpace(host))
+		if host == "" {
+			continue
+		}
+		if strings.ContainsAny(strings.TrimPrefix(host, "*."), "*/:@ ") {
+			return nil, fmt.Errorf("%q is not a host name", host)
+		}
+		hosts = append(hosts, host)
+	}
+	return hosts, nil
+}
+
+// newRateLimiter creates a named limiter on the configured backend
+func newRateLimiter(backend string, redisClient *redis.Client, name string, maxRequests int, window time.Duration) (middleware.RateLimiter, error) {
+	switch backend {
+	case "memory":
+		return middleware.NewMemoryRateLimiter(maxRequests, window), nil
+	case "redis":
+		return middleware.NewRedisRateLimiter(redisClient, name, maxRequests, window), nil
+	default:
+		return nil, fmt.Errorf("unknown RATE_LIMIT_BACKEND %q (expected memory or redis)", backend)
+	}
+}
+
+// newRedisClient connects to the Redis instance shared by rate limiters
+func newRedisClient(redisURL string) (*redis.Client, error) {
+	opts, err := redis.ParseURL(redisURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid REDIS_URL: %w", err)
+	}
+
+	client := redis.NewClient(opts)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := client.Ping(ctx).Err(); err != nil {
+		return nil, fmt.Errorf("failed to connect to redis: %w", err)
+	}
+
+	return client, nil
+}
+
+// defaultJWTSecret is the development-only fallback for JWT_SECRET
+const defaultJWTSecret = "your-development-secret-change-in-production"
+
+// minJWTSecretLength is the shortest JWT_SECRET accepted in production
+const minJWTSecretLength = 32
+
+// jwtSecretProblem describes why a JWT secret is unsafe, or returns "" if it isn't
+func jwtSecretProblem(secret string) string {
+	if secret == defaultJWTSecret {
+		return "is the built-in default"
+	}
+	if len(secret) < minJWTSecretLength {
+		return fmt.Sprintf("is shorter than %d characters", minJWTSecretLength)
+	}
+	return ""
+}
+
 func getEnv(key, defaultValue string) string {
 	if value := os.Getenv(key); value != "" {
 		return value
 	}
 	return defaultValue
-}
\ No newline at end of file
+}
+
+// getEnvInt reads an integer environment variable, exiting on malformed values
+func getEnvInt(key string, defaultValue int) int {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+
+	i, err := strconv.Atoi(value)
+	if err != nil {
+		log.Fatalf("Invalid configuration: %s must be an integer, got %q", key, value)
+	}
+	return i
+}
+
+// getEnvFloat reads a floating-point environment variable, exiting on malformed values
+func getEnvFloat(key string, defaultValue float64) float64 {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+
+	f, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		log.Fatalf("Invalid configuration: %s must be a number, got %q", key, value)
+	}
+	return f
+}
+
+// getEnvBool reads a boolean environment variable, exiting on malformed values
+func getEnvBool(key string, defaultValue bool) bool {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		log.Fatalf("Invalid configuration: %s must be a boolean, got %q", key, value)
+	}
+	return b
+}
@@ -5,11 +5,14 @@ go 1.25
 require (
 	github.com/golang-jwt/jwt/v5 v5.0.0
 	github.com/mattn/go-sqlite3 v1.14.17
+	github.com/redis/go-redis/v9 v9.7.3
 	golang.org/x/crypto v0.17.0
 )
 
 require (
+	github.com/cespare/xxhash/v2 v2.2.0 // indirect
 	github.com/davecgh/go-spew v1.1.1 // indirect
+	github.com/dgryski/go-rendezvous v0.0.0-20200823014737-9f7001d12a5f // indirect
 	github.com/pmezard/go-difflib v1.0.0 // indirect
 	github.com/stretchr/testify v1.8.4 // test
 	gopkg.in/yaml.v3 v3.0.1 // indirect
@@ -5,10 +5,10 @@ import (
 	"embed"
 	"fmt"
 	"io/fs"
+	"os"
 	"path/filepath"
 	"sort"
 	"strings"
-	"time"
 
 	_ "github.com/mattn/go-sqlite3"
 )
@@ -21,9 +21,17 @@ type DB struct {
 }
 
 func New(dbPath string) (*DB, error) {
-	// Connection string with optimizations as per documentation
+	if err := prepareDBPath(dbPath); err != nil {
+		return nil, err
+	}
+
+	// Connection string with optimizations as per documentation.
+	// Transactions begin IMMEDIATE: every one of them writes, and one that
+	// read first under a deferred BEGIN fails outright with
+	// SQLITE_BUSY_SNAPSHOT if another write commits before it can upgrade,
+	// rather than waiting out the busy timeout.
 	connStr := fmt.Sprintf(
-		"%s?_foreign_keys=on&_journal_mode=WAL&_synchronous=NORMAL&_cache_size=1000&_temp_store=memory&_timeout=5000",
+		"%s?_loc=UTC&_foreign_keys=on&_journal_mode=WAL&_synchronous=NORMAL&_cache_size=1000&_temp_store=memory&_timeout=5000&_txlock=immediate",
 		dbPath,
 	)
 
@@ -57,6 +65,36 @@ func New(dbPath string) (*DB, error) {
 	return db, nil
 }
 
+// prepareDBPath creates the database file's directory if needed and checks
+// that the file can be written, so a bad DB_PATH fails with a clear error
+// rather than SQLite's "unable to open database file"
+func prepareDBPath(dbPath string) error {
+	// In-memory databases and URIs have no directory to prepare
+	if dbPath == ":memory:" || strings.HasPrefix(dbPath, "file:") {
+		return nil
+	}
+
+	dir := filepath.Dir(dbPath)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("failed to create database directory %s: %w", dir, err)
+	}
+
+	// SQLite also writes -wal and -shm files beside the database, so the
+	// directory must be writable even when the file already exists
+	probe, err := os.CreateTemp(dir, ".write-check-*")
+	if err != nil {
+		return fmt.Errorf("database directory %s is not writable: %w", dir, err)
+	}
+	probe.Close()
+	os.Remove(probe.Name())
+
+	file, err := os.OpenFile(dbPath, os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return fmt.Errorf("database file %s is not writable: %w", dbPath, err)
+	}
+	return file.Close()
+}
+
 func (db *DB) migrate() error {
 	// Create migrations table if it doesn't exist
 	_, err := db.Exec(`
@@ -99,16 +137,36 @@ func (db *DB) migrate() error {
 				return fmt.Errorf("failed to read migration %s: %w", name, err)
 			}
 
+			statements := splitStatements(string(content))
+
+			// SQLite refuses some pragmas, such as synchronous, inside a
+			// transaction, so they run first on their own
+			for i, statement := range statements {
+				if !isPragma(statement) {
+					continue
+				}
+				if _, err := db.Exec(statement); err != nil {
+					return fmt.Errorf("failed to execute migration %s, statement %d (%s): %w",
+						name, i+1, statementSummary(statement), err)
+				}
+			}
+
 			// Execute migration in transaction
 			tx, err := db.Begin()
 			if err != nil {
 				return fmt.Errorf("failed to begin transaction for migration %s: %w", name, err)
 			}
 
-			_, err = tx.Exec(string(content))
-			if err != nil {
-				tx.Rollback()
-				return fmt.Errorf("failed to execute migration %s: %w", name, err)
+			// Run statements one at a time so a failure names the statement
+			for i, statement := range statements {
+				if isPragma(statement) {
+					continue
+				}
+				if _, err := tx.Exec(statement); err != nil {
+					tx.Rollback()
+					return fmt.Errorf("failed to execute migration %s, statement %d (%s): %w",
+						name, i+1, statementSummary(statement), err)
+				}
 			}
 
 			// Record migration
@@ -146,6 +204,16 @@ func (db *DB) configureProduction() error {
 		}
 	}
 
+	// Deleting an article relies on ON DELETE CASCADE to remove its comments,
+	// which SQLite silently skips when foreign keys are off
+	var foreignKeys int
+	if err := db.QueryRow("PRAGMA foreign_keys").Scan(&foreignKeys); err != nil {
+		return fmt.Errorf("failed to check foreign keys: %w", err)
+	}
+	if foreignKeys != 1 {
+		return fmt.Errorf("foreign key enforcement is disabled")
+	}
+
 	return nil
 }
 
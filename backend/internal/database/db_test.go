@@ -0,0 +1,33 @@
+package database
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestNewCreatesMissingDirectories(t *testing.T) {
+	db, err := New(filepath.Join(t.TempDir(), "a", "b", "rw.db"))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	defer db.Close()
+
+	var count int
+	if err := db.QueryRow("SELECT COUNT(*) FROM migrations").Scan(&count); err != nil {
+		t.Fatalf("counting migrations: %v", err)
+	}
+	if count == 0 {
+		t.Fatal("no migrations recorded")
+	}
+}
+
+func TestNewReopensExistingDatabase(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rw.db")
+	for i := 0; i < 2; i++ {
+		db, err := New(path)
+		if err != nil {
+			t.Fatalf("New (open %d): %v", i+1, err)
+		}
+		db.Close()
+	}
+}
@@ -0,0 +1,17 @@
+-- User changes table - Audit trail of email and username changes
+CREATE TABLE user_changes (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id INTEGER NOT NULL,
+    field VARCHAR(50) NOT NULL,
+    old_value VARCHAR(255) NOT NULL,
+    new_value VARCHAR(255) NOT NULL,
+    changed_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+
+    -- Foreign key relationships
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+
+    -- Constraints
+    CONSTRAINT user_change_field CHECK (field IN ('email', 'username'))
+);
+
+CREATE INDEX idx_user_changes_user_id ON user_changes(user_id, changed_at DESC);
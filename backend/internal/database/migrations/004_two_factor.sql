@@ -0,0 +1,21 @@
+-- Two-factor authentication - TOTP enrollment and recovery codes
+
+-- Encrypted TOTP secret; enabled only after the user confirms a code
+ALTER TABLE users ADD COLUMN totp_secret TEXT NOT NULL DEFAULT '';
+ALTER TABLE users ADD COLUMN totp_enabled BOOLEAN NOT NULL DEFAULT 0;
+
+-- Recovery codes table - Single-use fallback codes, stored hashed
+CREATE TABLE recovery_codes (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id INTEGER NOT NULL,
+    code_hash VARCHAR(64) NOT NULL,
+    used_at DATETIME,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+
+    -- Foreign key relationships
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+
+    UNIQUE (user_id, code_hash)
+);
+
+CREATE INDEX idx_recovery_codes_user_id ON recovery_codes(user_id);
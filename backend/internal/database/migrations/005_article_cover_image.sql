@@ -0,0 +1,2 @@
+-- Article cover images - optional URL shown above the article
+ALTER TABLE articles ADD COLUMN cover_image VARCHAR(500);
@@ -0,0 +1,12 @@
+-- Comment likes table - Users liking comments (many-to-many)
+CREATE TABLE comment_likes (
+    user_id INTEGER NOT NULL,
+    comment_id INTEGER NOT NULL,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+
+    PRIMARY KEY (user_id, comment_id),
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    FOREIGN KEY (comment_id) REFERENCES comments(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_comment_likes_comment_id ON comment_likes(comment_id);
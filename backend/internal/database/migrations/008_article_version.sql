@@ -0,0 +1,2 @@
+-- Article versions - incremented on every update for optimistic concurrency
+ALTER TABLE articles ADD COLUMN version INTEGER NOT NULL DEFAULT 1;
@@ -0,0 +1,12 @@
+-- Invite codes table - Single-use codes allowing signup when registration is closed
+CREATE TABLE invite_codes (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    code VARCHAR(64) UNIQUE NOT NULL,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    expires_at DATETIME,
+    used_at DATETIME,
+    used_by INTEGER,
+
+    -- Foreign key relationships
+    FOREIGN KEY (used_by) REFERENCES users(id) ON DELETE SET NULL
+);
@@ -0,0 +1,2 @@
+-- Per-article switch authors can use to close comments
+ALTER TABLE articles ADD COLUMN comments_enabled BOOLEAN NOT NULL DEFAULT 1;
@@ -0,0 +1,2 @@
+-- Tokens issued before this time are rejected; set on password change
+ALTER TABLE users ADD COLUMN tokens_valid_after DATETIME;
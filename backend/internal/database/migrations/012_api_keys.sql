@@ -0,0 +1,15 @@
+-- API keys table - Long-lived credentials for scripts, stored hashed
+CREATE TABLE api_keys (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id INTEGER NOT NULL,
+    name VARCHAR(100) NOT NULL DEFAULT '',
+    key_hash VARCHAR(64) NOT NULL UNIQUE,
+    -- Leading characters of the key, so users can tell their keys apart
+    prefix VARCHAR(16) NOT NULL,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+
+    -- Foreign key relationships
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_api_keys_user_id ON api_keys(user_id);
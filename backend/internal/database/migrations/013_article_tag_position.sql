@@ -0,0 +1,11 @@
+-- Article tag positions - tagList is returned in the order the author gave it
+ALTER TABLE article_tags ADD COLUMN position INTEGER NOT NULL DEFAULT 0;
+
+-- Existing tags keep their previous alphabetical order
+UPDATE article_tags SET position = (
+    SELECT COUNT(*)
+    FROM article_tags other
+    JOIN tags other_tag ON other.tag_id = other_tag.id
+    WHERE other.article_id = article_tags.article_id
+      AND other_tag.name < (SELECT name FROM tags WHERE id = article_tags.tag_id)
+);
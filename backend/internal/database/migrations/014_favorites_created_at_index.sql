@@ -0,0 +1,2 @@
+-- Trending tags count favorites within a recent window
+CREATE INDEX IF NOT EXISTS idx_favorites_created_at ON favorites(created_at);
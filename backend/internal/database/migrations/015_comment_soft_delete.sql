@@ -0,0 +1,2 @@
+-- Soft-deleted comments stay in place as tombstones so threads keep their shape
+ALTER TABLE comments ADD COLUMN deleted_at DATETIME;
@@ -0,0 +1,2 @@
+-- Drafts are only visible to their author until published
+ALTER TABLE articles ADD COLUMN published BOOLEAN NOT NULL DEFAULT 1;
@@ -0,0 +1,10 @@
+-- Follows made per user per UTC day, for the daily follow limit. Kept apart
+-- from follows so unfollowing doesn't give the budget back.
+CREATE TABLE daily_follow_counts (
+    user_id INTEGER NOT NULL,
+    day DATE NOT NULL,
+    count INTEGER NOT NULL DEFAULT 0,
+
+    PRIMARY KEY (user_id, day),
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
@@ -0,0 +1,13 @@
+-- Last successful login, for the user's own security review
+ALTER TABLE users ADD COLUMN last_login_at DATETIME;
+ALTER TABLE users ADD COLUMN last_login_ip VARCHAR(45) NOT NULL DEFAULT '';
+
+-- Recording a login isn't a profile change, so it leaves updated_at alone
+DROP TRIGGER users_updated_at;
+CREATE TRIGGER users_updated_at
+    AFTER UPDATE ON users
+    FOR EACH ROW
+    WHEN OLD.updated_at = NEW.updated_at AND OLD.last_login_at IS NEW.last_login_at
+BEGIN
+    UPDATE users SET updated_at = CURRENT_TIMESTAMP WHERE id = NEW.id;
+END;
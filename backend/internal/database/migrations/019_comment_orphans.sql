@@ -0,0 +1,6 @@
+-- comments already cascade from articles and users (001_initial_schema), but a
+-- database once opened without foreign_keys can hold comments whose article
+-- or author is gone. Clear them so the constraints hold for every row.
+DELETE FROM comments
+WHERE article_id NOT IN (SELECT id FROM articles)
+   OR author_id NOT IN (SELECT id FROM users);
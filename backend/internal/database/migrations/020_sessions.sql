@@ -0,0 +1,16 @@
+-- Sessions table - One row per login, shared by the tokens refreshed from it
+-- (their sid claim), so users can see where they are signed in and revoke it
+CREATE TABLE sessions (
+    id VARCHAR(32) PRIMARY KEY,
+    user_id INTEGER NOT NULL,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    last_seen_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    -- When the session's newest token expires
+    expires_at DATETIME NOT NULL,
+    revoked_at DATETIME,
+
+    -- Foreign key relationships
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_sessions_user_id ON sessions(user_id);
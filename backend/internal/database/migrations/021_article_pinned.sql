@@ -0,0 +1,14 @@
+-- An author may pin one article to the top of their profile listing
+ALTER TABLE articles ADD COLUMN pinned BOOLEAN NOT NULL DEFAULT 0;
+
+CREATE UNIQUE INDEX idx_articles_pinned_author ON articles(author_id) WHERE pinned = 1;
+
+-- Pinning isn't an edit, so it leaves updated_at alone
+DROP TRIGGER articles_updated_at;
+CREATE TRIGGER articles_updated_at
+    AFTER UPDATE ON articles
+    FOR EACH ROW
+    WHEN OLD.updated_at = NEW.updated_at AND OLD.pinned IS NEW.pinned
+BEGIN
+    UPDATE articles SET updated_at = CURRENT_TIMESTAMP WHERE id = NEW.id;
+END;
@@ -0,0 +1,18 @@
+-- Article transfers table - Audit trail of article ownership changes
+CREATE TABLE article_transfers (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    article_id INTEGER NOT NULL,
+    from_user_id INTEGER,
+    to_user_id INTEGER,
+    -- The author or admin who made the transfer
+    transferred_by INTEGER,
+    transferred_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+
+    -- Foreign key relationships; the trail outlives the users involved
+    FOREIGN KEY (article_id) REFERENCES articles(id) ON DELETE CASCADE,
+    FOREIGN KEY (from_user_id) REFERENCES users(id) ON DELETE SET NULL,
+    FOREIGN KEY (to_user_id) REFERENCES users(id) ON DELETE SET NULL,
+    FOREIGN KEY (transferred_by) REFERENCES users(id) ON DELETE SET NULL
+);
+
+CREATE INDEX idx_article_transfers_article_id ON article_transfers(article_id, transferred_at DESC);
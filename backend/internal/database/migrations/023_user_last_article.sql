@@ -0,0 +1,19 @@
+-- When the user last created an article, for the creation cooldown. Imports
+-- may backdate created_at, so the articles themselves can't be used.
+ALTER TABLE users ADD COLUMN last_article_at DATETIME;
+
+UPDATE users SET last_article_at = (
+    SELECT MAX(created_at) FROM articles WHERE articles.author_id = users.id
+);
+
+-- Recording an article creation isn't a profile change either
+DROP TRIGGER users_updated_at;
+CREATE TRIGGER users_updated_at
+    AFTER UPDATE ON users
+    FOR EACH ROW
+    WHEN OLD.updated_at = NEW.updated_at
+        AND OLD.last_login_at IS NEW.last_login_at
+        AND OLD.last_article_at IS NEW.last_article_at
+BEGIN
+    UPDATE users SET updated_at = CURRENT_TIMESTAMP WHERE id = NEW.id;
+END;
@@ -0,0 +1,143 @@
+package database
+
+import (
+	"strings"
+	"unicode"
+)
+
+// splitStatements splits a migration script into individual statements on
+// top-level semicolons. Semicolons inside string literals, quoted identifiers,
+// comments, and trigger bodies (BEGIN ... END) don't end a statement.
+// Statements consisting only of whitespace and comments are dropped.
+func splitStatements(script string) []string {
+	var statements []string
+	var current strings.Builder
+	hasCode := false
+
+	// Trigger tracking: the first words of the statement, and the nesting of
+	// BEGIN/CASE ... END blocks once inside a trigger
+	var leadingWords []string
+	isTrigger := false
+	depth := 0
+
+	flush := func() {
+		if hasCode {
+			statements = append(statements, strings.TrimSpace(current.String()))
+		}
+		current.Reset()
+		hasCode = false
+		leadingWords = nil
+		isTrigger = false
+		depth = 0
+	}
+
+	for i := 0; i < len(script); {
+		c := script[i]
+
+		switch {
+		case c == '-' && i+1 < len(script) && script[i+1] == '-':
+			end := strings.IndexByte(script[i:], '\n')
+			if end < 0 {
+				end = len(script) - i
+			}
+			current.WriteString(script[i : i+end])
+			i += end
+
+		case c == '/' && i+1 < len(script) && script[i+1] == '*':
+			end := strings.Index(script[i+2:], "*/")
+			if end < 0 {
+				end = len(script) - i
+			} else {
+				end += 4
+			}
+			current.WriteString(script[i : i+end])
+			i += end
+
+		case c == '\'' || c == '"' || c == '`':
+			// Quoted literal or identifier; a doubled quote is an escape
+			j := i + 1
+			for j < len(script) {
+				if script[j] == c {
+					if j+1 < len(script) && script[j+1] == c {
+						j += 2
+						continue
+					}
+					j++
+					break
+				}
+				j++
+			}
+			current.WriteString(script[i:j])
+			hasCode = true
+			i = j
+
+		case c == ';':
+			current.WriteByte(c)
+			i++
+			if depth == 0 {
+				flush()
+			}
+
+		case isWordByte(c):
+			j := i
+			for j < len(script) && isWordByte(script[j]) {
+				j++
+			}
+			word := strings.ToUpper(script[i:j])
+			current.WriteString(script[i:j])
+			hasCode = true
+			i = j
+
+			if len(leadingWords) < 4 {
+				leadingWords = append(leadingWords, word)
+				isTrigger = isTrigger || (leadingWords[0] == "CREATE" && word == "TRIGGER")
+			}
+			if isTrigger {
+				switch word {
+				case "BEGIN", "CASE":
+					depth++
+				case "END":
+					if depth > 0 {
+						depth--
+					}
+				}
+			}
+
+		default:
+			current.WriteByte(c)
+			if !unicode.IsSpace(rune(c)) {
+				hasCode = true
+			}
+			i++
+		}
+	}
+	flush()
+
+	return statements
+}
+
+func isWordByte(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')
+}
+
+// statementSummary shortens a statement for error messages, skipping the
+// comment lines that usually lead it
+func statementSummary(statement string) string {
+	var code []string
+	for _, line := range strings.Split(statement, "\n") {
+		if !strings.HasPrefix(strings.TrimSpace(line), "--") {
+			code = append(code, line)
+		}
+	}
+
+	summary := strings.Join(strings.Fields(strings.Join(code, "\n")), " ")
+	if len(summary) > 120 {
+		summary = summary[:117] + "..."
+	}
+	return summary
+}
+
+// isPragma reports whether a statement from splitStatements is a PRAGMA
+func isPragma(statement string) bool {
+	return strings.HasPrefix(strings.ToUpper(statementSummary(statement)), "PRAGMA")
+}
@@ -0,0 +1,135 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/realworld/backend/internal/middleware"
+	"github.com/realworld/backend/internal/models"
+	"github.com/realworld/backend/internal/utils"
+)
+
+// IsAdmin reports whether the user is a configured admin. The username is
+// read from the database so a token issued before a rename can't keep or
+// gain admin rights.
+func (h *Handler) IsAdmin(user *middleware.User) bool {
+	if len(h.Admins) == 0 {
+		return false
+	}
+
+	var username string
+	if err := h.DB.QueryRow("SELECT username FROM users WHERE id = ?", user.ID).Scan(&username); err != nil {
+		return false
+	}
+	return h.Admins[strings.ToLower(username)]
+}
+
+// CreateInvites generates a batch of single-use invite codes
+func (h *Handler) CreateInvites(w http.ResponseWriter, r *http.Request) {
+	var req models.CreateInvitesRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		models.WriteErrorResponse(w, http.StatusBadRequest, "Invalid JSON format")
+		return
+	}
+
+	if errs := req.Validate(); errs != nil {
+		models.WriteErrorResponse(w, http.StatusUnprocessableEntity, errs)
+		return
+	}
+
+	now := time.Now().UTC()
+	createdAt := models.NewTimestamp(now)
+	expiresAt := models.NewTimestamp(now.Add(time.Duration(*req.ExpiresInHours) * time.Hour))
+
+	tx, err := h.DB.Begin()
+	if err != nil {
+		h.Logger.Printf("Database error starting invite transaction: %v", err)
+		models.WriteErrorResponse(w, http.StatusInternalServerError, "Internal server error")
+		return
+	}
+	defer tx.Rollback()
+
+	invites := make([]models.Invite, 0, req.Count)
+	for i := 0; i < req.Count; i++ {
+		code, err := utils.GenerateInviteCode()
+		if err != nil {
+			h.Logger.Printf("Error generating invite code: %v", err)
+			models.WriteErrorResponse(w, http.StatusInternalServerError, "Internal server error")
+			return
+		}
+
+		// Stored in SQLite's own format so expiry compares against CURRENT_TIMESTAMP
+		if _, err := tx.Exec(`
+			INSERT INTO invite_codes (code, created_at, expires_at)
+			VALUES (?, ?, ?)
+		`, code, now.Format(sqliteTimeFormat), expiresAt.Format(sqliteTimeFormat)); err != nil {
+			h.Logger.Printf("Database error creating invite code: %v", err)
+			models.WriteErrorResponse(w, http.StatusInternalServerError, "Internal server error")
+			return
+		}
+
+		expires := expiresAt
+		invites = append(invites, models.Invite{
+			Code:      code,
+			CreatedAt: createdAt,
+			ExpiresAt: &expires,
+		})
+	}
+
+	if err := tx.Commit(); err != nil {
+		h.Logger.Printf("Database error committing invite codes: %v", err)
+		models.WriteErrorResponse(w, http.StatusInternalServerError, "Internal server error")
+		return
+	}
+
+	models.WriteJSONResponse(w, http.StatusCreated, models.InvitesResponse{Invites: invites})
+}
+
+// ListInvites lists invite codes, newest first, with the account each one created
+func (h *Handler) ListInvites(w http.ResponseWriter, r *http.Request) {
+	limit, offset, errs := parsePagination(r.URL.Query())
+	if errs != nil {
+		models.WriteErrorResponse(w, http.StatusUnprocessableEntity, errs)
+		return
+	}
+	if h.offsetTooDeep(w, offset) {
+		return
+	}
+
+	rows, err := h.DB.Query(`
+		SELECT i.code, i.created_at, i.expires_at, i.used_at, u.username
+		FROM invite_codes i
+		LEFT JOIN users u ON i.used_by = u.id
+		ORDER BY i.created_at DESC, i.id DESC
+		LIMIT ? OFFSET ?
+	`, limit, offset)
+	if err != nil {
+		h.Logger.Printf("Database error listing invite codes: %v", err)
+		models.WriteErrorResponse(w, http.StatusInternalServerError, "Internal server error")
+		return
+	}
+	defer rows.Close()
+
+	invites := make([]models.Invite, 0)
+	for rows.Next() {
+		var invite models.Invite
+		if err := rows.Scan(&invite.Code, &invite.CreatedAt, &invite.ExpiresAt, &invite.UsedAt, &invite.UsedBy); err != nil {
+			h.Logger.Printf("Error scanning invite code row: %v", err)
+			models.WriteErrorResponse(w, http.StatusInternalServerError, "Internal server error")
+			return
+		}
+		invites = append(invites, invite)
+	}
+	if err := rows.Err(); err != nil {
+		h.Logger.Printf("Database error listing invite codes: %v", err)
+		models.WriteErrorResponse(w, http.StatusInternalServerError, "Internal server error")
+		return
+	}
+
+	models.WriteJSONResponse(w, http.StatusOK, models.InvitesResponse{Invites: invites})
+}
+
+// sqliteTimeFormat matches CURRENT_TIMESTAMP so stored times compare correctly as text
+const sqliteTimeFormat = "2006-01-02 15:04:05"
@@ -0,0 +1,206 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/realworld/backend/internal/middleware"
+	"github.com/realworld/backend/internal/models"
+	"github.com/realworld/backend/internal/utils"
+)
+
+// maxAPIKeysPerUser caps how many API keys one user can hold
+const maxAPIKeysPerUser = 20
+
+// apiKeyDisplayLength is how much of a key is kept in the clear to identify it
+const apiKeyDisplayLength = len(utils.APIKeyPrefix) + 8
+
+// ResolveAPIKey implements middleware.APIKeyResolver, looking keys up by hash
+func (h *Handler) ResolveAPIKey(key string) (*middleware.User, error) {
+	var user middleware.User
+	err := h.DB.QueryRow(`
+		SELECT k.id, u.id, u.username
+		FROM api_keys k
+		JOIN users u ON k.user_id = u.id
+		WHERE k.key_hash = ?
+	`, utils.HashToken(key)).Scan(&user.APIKeyID, &user.ID, &user.Username)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		h.Logger.Printf("Database error resolving API key: %v", err)
+		return nil, err
+	}
+	return &user, nil
+}
+
+// requireSessionAuth rejects requests authenticated with an API key with the
+// given message, so a leaked key can't be turned into further credentials
+func requireSessionAuth(w http.ResponseWriter, r *http.Request, message string) (*middleware.User, bool) {
+	authUser, ok := middleware.GetUserFromContext(r.Context())
+	if !ok {
+		models.WriteErrorResponse(w, http.StatusUnauthorized, "Unauthorized")
+		return nil, false
+	}
+
+	if authUser.APIKeyID != 0 {
+		models.WriteErrorResponse(w, http.StatusForbidden, message)
+		return nil, false
+	}
+
+	return authUser, true
+}
+
+// refreshToken issues a fresh JWT for a user response. Requests made with an
+// API key get none, so a key can't be exchanged for a session token.
+func (h *Handler) refreshToken(authUser *middleware.User, userID int, username string) (string, error) {
+	if authUser.APIKeyID != 0 {
+		return "", nil
+	}
+	return h.issueToken(authUser.SessionID, userID, username)
+}
+
+// ListAPIKeys lists the current user's API keys without the keys themselves
+func (h *Handler) ListAPIKeys(w http.ResponseWriter, r *http.Request) {
+	authUser, ok := requireSessionAuth(w, r, "API keys can't be managed with an API key")
+	if !ok {
+		return
+	}
+
+	rows, err := h.DB.Query(`
+		SELECT id, name, prefix, created_at
+		FROM api_keys
+		WHERE user_id = ?
+		ORDER BY created_at DESC, id DESC
+	`, authUser.ID)
+	if err != nil {
+		h.Logger.Printf("Database error listing API keys: %v", err)
+		models.WriteErrorResponse(w, http.StatusInternalServerError, "Internal server error")
+		return
+	}
+	defer rows.Close()
+
+	keys := make([]models.APIKey, 0)
+	for rows.Next() {
+		var key models.APIKey
+		if err := rows.Scan(&key.ID, &key.Name, &key.Prefix, &key.CreatedAt); err != nil {
+			h.Logger.Printf("Error scanning API key row: %v", err)
+			models.WriteErrorResponse(w, http.StatusInternalServerError, "Internal server error")
+			return
+		}
+		keys = append(keys, key)
+	}
+	if err := rows.Err(); err != nil {
+		h.Logger.Printf("Database error listing API keys: %v", err)
+		models.WriteErrorResponse(w, http.StatusInternalServerError, "Internal server error")
+		return
+	}
+
+	models.WriteJSONResponse(w, http.StatusOK, models.APIKeysResponse{APIKeys: keys})
+}
+
+// CreateAPIKey issues a new API key. The key is only ever returned here;
+// just its hash is stored.
+func (h *Handler) CreateAPIKey(w http.ResponseWriter, r *http.Request) {
+	authUser, ok := requireSessionAuth(w, r, "API keys can't be managed with an API key")
+	if !ok {
+		return
+	}
+
+	var req models.CreateAPIKeyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		models.WriteErrorResponse(w, http.StatusBadRequest, "Invalid JSON format")
+		return
+	}
+
+	if errs := req.Validate(); errs != nil {
+		models.WriteErrorResponse(w, http.StatusUnprocessableEntity, errs)
+		return
+	}
+
+	var keyCount int
+	if err := h.DB.QueryRow("SELECT COUNT(*) FROM api_keys WHERE user_id = ?", authUser.ID).Scan(&keyCount); err != nil {
+		h.Logger.Printf("Database error counting API keys: %v", err)
+		models.WriteErrorResponse(w, http.StatusInternalServerError, "Internal server error")
+		return
+	}
+	if keyCount >= maxAPIKeysPerUser {
+		models.WriteErrorResponse(w, http.StatusUnprocessableEntity, models.ValidationErrors{
+			{Field: "apiKey", Message: "limit of " + strconv.Itoa(maxAPIKeysPerUser) + " keys reached; revoke one first"},
+		})
+		return
+	}
+
+	key, err := utils.GenerateAPIKey()
+	if err != nil {
+		h.Logger.Printf("Error generating API key: %v", err)
+		models.WriteErrorResponse(w, http.StatusInternalServerError, "Internal server error")
+		return
+	}
+
+	prefix := key[:apiKeyDisplayLength]
+	result, err := h.DB.Exec(`
+		INSERT INTO api_keys (user_id, name, key_hash, prefix)
+		VALUES (?, ?, ?, ?)
+	`, authUser.ID, req.APIKey.Name, utils.HashToken(key), prefix)
+	if err != nil {
+		h.Logger.Printf("Database error creating API key: %v", err)
+		models.WriteErrorResponse(w, http.StatusInternalServerError, "Internal server error")
+		return
+	}
+
+	keyID, err := result.LastInsertId()
+	if err != nil {
+		h.Logger.Printf("Error getting API key ID: %v", err)
+		models.WriteErrorResponse(w, http.StatusInternalServerError, "Internal server error")
+		return
+	}
+
+	response := models.APIKeyResponse{APIKey: models.APIKey{
+		ID:     int(keyID),
+		Name:   req.APIKey.Name,
+		Prefix: prefix,
+		Key:    key,
+	}}
+	if err := h.DB.QueryRow("SELECT created_at FROM api_keys WHERE id = ?", keyID).Scan(&response.APIKey.CreatedAt); err != nil {
+		h.Logger.Printf("Database error getting created API key: %v", err)
+		models.WriteErrorResponse(w, http.StatusInternalServerError, "Internal server error")
+		return
+	}
+
+	models.WriteJSONResponse(w, http.StatusCreated, response)
+}
+
+// RevokeAPIKey deletes one of the current user's API keys
+func (h *Handler) RevokeAPIKey(w http.ResponseWriter, r *http.Request) {
+	authUser, ok := requireSessionAuth(w, r, "API keys can't be managed with an API key")
+	if !ok {
+		return
+	}
+
+	keyID, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		models.WriteErrorResponse(w, http.StatusNotFound, "API key not found")
+		return
+	}
+
+	// Scoping the delete to the user makes other users' keys look missing
+	result, err := h.DB.Exec("DELETE FROM api_keys WHERE id = ? AND user_id = ?", keyID, authUser.ID)
+	if err != nil {
+		h.Logger.Printf("Database error revoking API key: %v", err)
+		models.WriteErrorResponse(w, http.StatusInternalServerError, "Internal server error")
+		return
+	}
+
+	if revoked, _ := result.RowsAffected(); revoked == 0 {
+		models.WriteErrorResponse(w, http.StatusNotFound, "API key not found")
+		return
+	}
+
+	// Return 200 OK with empty response
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte("{}"))
+}
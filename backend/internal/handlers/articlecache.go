@@ -0,0 +1,96 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/realworld/backend/internal/models"
+)
+
+// maxArticleListCacheEntries bounds how many distinct queries are cached, so
+// varying the query string can't grow the cache without limit
+const maxArticleListCacheEntries = 256
+
+// articleListCache holds anonymous ListArticles results by normalized query
+// string. Anonymous results carry no per-user flags, so one entry serves
+// every anonymous caller.
+type articleListCache struct {
+	mu      sync.Mutex
+	entries map[string]articleListEntry
+}
+
+type articleListEntry struct {
+	articles    []models.Article
+	totalCount  int
+	suggestions []models.Article
+	expires     time.Time
+}
+
+// cachedArticleList returns a copy of the cached result for key, if still
+// fresh
+func (h *Handler) cachedArticleList(key string) (articleListEntry, bool) {
+	h.articleLists.mu.Lock()
+	defer h.articleLists.mu.Unlock()
+
+	entry, ok := h.articleLists.entries[key]
+	if !ok || !time.Now().Before(entry.expires) {
+		return articleListEntry{}, false
+	}
+	return entry.clone(), true
+}
+
+// cacheArticleList stores a result for ArticleListCacheTTL. When the cache
+// is full of fresh entries the result is simply not cached.
+func (h *Handler) cacheArticleList(key string, entry articleListEntry) {
+	h.articleLists.mu.Lock()
+	defer h.articleLists.mu.Unlock()
+
+	now := time.Now()
+	if h.articleLists.entries == nil {
+		h.articleLists.entries = make(map[string]articleListEntry)
+	}
+	if len(h.articleLists.entries) >= maxArticleListCacheEntries {
+		for k, e := range h.articleLists.entries {
+			if !now.Before(e.expires) {
+				delete(h.articleLists.entries, k)
+			}
+		}
+		if len(h.articleLists.entries) >= maxArticleListCacheEntries {
+			return
+		}
+	}
+
+	entry = entry.clone()
+	entry.expires = now.Add(h.ArticleListCacheTTL)
+	h.articleLists.entries[key] = entry
+}
+
+// clone copies the entry's slices, since writeArticlesResponse fills in
+// excerpts on the articles it is given
+func (e articleListEntry) clone() articleListEntry {
+	e.articles = append(make([]models.Article, 0, len(e.articles)), e.articles...)
+	e.suggestions = append([]models.Article(nil), e.suggestions...)
+	return e
+}
+
+// invalidateArticleLists drops every cached ListArticles result; call it
+// after anything that changes what anonymous readers see in the list
+func (h *Handler) invalidateArticleLists() {
+	h.articleLists.mu.Lock()
+	h.articleLists.entries = nil
+	h.articleLists.mu.Unlock()
+}
+
+// setArticleListCacheHeaders tells clients and shared caches how long an
+// article list may be reused: anonymous lists for ArticleListCacheTTL,
+// personalized ones not at all
+func (h *Handler) setArticleListCacheHeaders(w http.ResponseWriter, anonymous bool) {
+	w.Header().Add("Vary", "Authorization")
+	if anonymous {
+		w.Header().Set("Cache-Control", "public, max-age="+strconv.Itoa(int(h.ArticleListCacheTTL/time.Second)))
+	} else {
+		w.Header().Set("Cache-Control", "private, no-cache")
+	}
+}
@@ -0,0 +1,180 @@
+package handlers
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"image"
+	_ "image/gif"  // register GIF decoder for dimension checks
+	_ "image/jpeg" // register JPEG decoder for dimension checks
+	_ "image/png"  // register PNG decoder for dimension checks
+	"io"
+	"mime"
+	"net/http"
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/realworld/backend/internal/middleware"
+	"github.com/realworld/backend/internal/models"
+	"github.com/realworld/backend/internal/storage"
+)
+
+// avatarExtensions maps accepted image content types to file extensions
+var avatarExtensions = map[string]string{
+	"image/png":  ".png",
+	"image/jpeg": ".jpg",
+	"image/gif":  ".gif",
+}
+
+// imageIDPattern matches the ids generated for stored images, which keeps
+// GetImage from ever resolving a path outside the upload directory
+var imageIDPattern = regexp.MustCompile(`^[a-f0-9]{32}\.(png|jpg|gif)$`)
+
+// UploadAvatar accepts a multipart image upload in the "image" field, stores it
+// and points the current user's image at the stored image's URL
+func (h *Handler) UploadAvatar(w http.ResponseWriter, r *http.Request) {
+	// Get user from context (set by auth middleware)
+	authUser, ok := middleware.GetUserFromContext(r.Context())
+	if !ok {
+		models.WriteErrorResponse(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	// Leave headroom for the multipart envelope around the file itself
+	r.Body = http.MaxBytesReader(w, r.Body, h.AvatarMaxBytes+64*1024)
+
+	file, _, err := r.FormFile("image")
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			models.WriteErrorResponse(w, http.StatusRequestEntityTooLarge, "Image is too large")
+			return
+		}
+		models.WriteErrorResponse(w, http.StatusBadRequest, "Multipart form with an image field is required")
+		return
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(io.LimitReader(file, h.AvatarMaxBytes+1))
+	if err != nil {
+		models.WriteErrorResponse(w, http.StatusBadRequest, "Failed to read image")
+		return
+	}
+
+	if int64(len(data)) > h.AvatarMaxBytes {
+		models.WriteErrorResponse(w, http.StatusRequestEntityTooLarge, "Image is too large")
+		return
+	}
+
+	// Validate the actual content rather than the client-supplied content type
+	var errors models.ValidationErrors
+	ext, ok := avatarExtensions[http.DetectContentType(data)]
+	if !ok {
+		errors = append(errors, models.ValidationError{Field: "image", Message: "must be a PNG, JPEG or GIF image"})
+		models.WriteErrorResponse(w, http.StatusUnprocessableEntity, errors)
+		return
+	}
+
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		errors = append(errors, models.ValidationError{Field: "image", Message: "could not be decoded"})
+		models.WriteErrorResponse(w, http.StatusUnprocessableEntity, errors)
+		return
+	}
+
+	if config.Width > h.AvatarMaxDimension || config.Height > h.AvatarMaxDimension {
+		errors = append(errors, models.ValidationError{Field: "image", Message: "dimensions exceed the maximum allowed"})
+		models.WriteErrorResponse(w, http.StatusUnprocessableEntity, errors)
+		return
+	}
+
+	id, err := newImageID(ext)
+	if err != nil {
+		h.Logger.Printf("Image id generation error: %v", err)
+		models.WriteErrorResponse(w, http.StatusInternalServerError, "Internal server error")
+		return
+	}
+
+	var previousImage string
+	err = h.DB.QueryRow("SELECT image FROM users WHERE id = ?", authUser.ID).Scan(&previousImage)
+	if err != nil {
+		h.Logger.Printf("Database error getting user image: %v", err)
+		models.WriteErrorResponse(w, http.StatusInternalServerError, "Internal server error")
+		return
+	}
+
+	if err := h.Storage.Put(r.Context(), id, data, mime.TypeByExtension(ext)); err != nil {
+		h.Logger.Printf("Error storing image: %v", err)
+		models.WriteErrorResponse(w, http.StatusInternalServerError, "Internal server error")
+		return
+	}
+
+	if _, err := h.DB.Exec("UPDATE users SET image = ? WHERE id = ?", h.imageURL(r, id), authUser.ID); err != nil {
+		h.Logger.Printf("Database error updating user image: %v", err)
+		models.WriteErrorResponse(w, http.StatusInternalServerError, "Internal server error")
+		return
+	}
+
+	// Clean up the avatar this upload replaced, if we stored it
+	if oldID := path.Base(previousImage); imageIDPattern.MatchString(oldID) &&
+		strings.HasSuffix(previousImage, h.Storage.URL(oldID)) {
+		if err := h.Storage.Delete(r.Context(), oldID); err != nil {
+			h.Logger.Printf("Error deleting replaced image %s: %v", oldID, err)
+		}
+	}
+
+	// Respond like GetCurrentUser so clients get the refreshed user
+	h.GetCurrentUser(w, r)
+}
+
+// GetImage serves a stored image by id
+func (h *Handler) GetImage(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	if !imageIDPattern.MatchString(id) {
+		models.WriteErrorResponse(w, http.StatusNotFound, "Image not found")
+		return
+	}
+
+	object, err := h.Storage.Get(r.Context(), id)
+	if errors.Is(err, storage.ErrNotFound) {
+		models.WriteErrorResponse(w, http.StatusNotFound, "Image not found")
+		return
+	}
+
+	if err != nil {
+		h.Logger.Printf("Error opening image: %v", err)
+		models.WriteErrorResponse(w, http.StatusInternalServerError, "Internal server error")
+		return
+	}
+	defer object.Close()
+
+	// Image ids are never reused, so responses can be cached indefinitely
+	w.Header().Set("Content-Type", mime.TypeByExtension(path.Ext(id)))
+	w.Header().Set("Cache-Control", "public, max-age=31536000, immutable")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(http.StatusOK)
+	io.Copy(w, object)
+}
+
+// imageURL returns the absolute URL clients should use for a stored image
+func (h *Handler) imageURL(r *http.Request, id string) string {
+	u := h.Storage.URL(id)
+	if strings.HasPrefix(u, "/") {
+		u = h.baseURL(r) + u
+	}
+	if models.RequireHTTPSImages && strings.HasPrefix(u, "http://") {
+		u = "https://" + strings.TrimPrefix(u, "http://")
+	}
+	return u
+}
+
+// newImageID generates a random, unguessable image id with the given extension
+func newImageID(ext string) (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b) + ext, nil
+}
@@ -0,0 +1,63 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/realworld/backend/internal/middleware"
+)
+
+// ParsePublicBaseURL validates a configured external base URL such as
+// https://api.example.com, returning it without a trailing slash
+func ParsePublicBaseURL(value string) (string, error) {
+	u, err := url.Parse(value)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return "", fmt.Errorf("must be an absolute http or https URL, got %q", value)
+	}
+	if u.RawQuery != "" || u.Fragment != "" {
+		return "", fmt.Errorf("must not have a query or fragment, got %q", value)
+	}
+	return strings.TrimSuffix(u.String(), "/"), nil
+}
+
+// baseURL returns the scheme and host clients use to reach the API, for
+// building absolute links. X-Forwarded-Proto and X-Forwarded-Host are used
+// when a trusted proxy sent them, then PublicBaseURL, then the request itself.
+func (h *Handler) baseURL(r *http.Request) string {
+	if middleware.FromTrustedProxy(r) {
+		proto := middleware.ForwardedValue(r, "X-Forwarded-Proto")
+		if proto != "http" && proto != "https" {
+			proto = ""
+		}
+		host := middleware.ForwardedValue(r, "X-Forwarded-Host")
+		if strings.ContainsAny(host, "/\\@ ") {
+			host = ""
+		}
+
+		if proto != "" || host != "" {
+			if proto == "" {
+				proto = requestScheme(r)
+			}
+			if host == "" {
+				host = r.Host
+			}
+			return proto + "://" + host
+		}
+	}
+
+	if h.PublicBaseURL != "" {
+		return h.PublicBaseURL
+	}
+
+	return requestScheme(r) + "://" + r.Host
+}
+
+// requestScheme returns the scheme of the connection the request arrived on
+func requestScheme(r *http.Request) string {
+	if r.TLS != nil {
+		return "https"
+	}
+	return "http"
+}
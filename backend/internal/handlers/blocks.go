@@ -0,0 +1,134 @@
+package handlers
+
+import (
+	"database/sql"
+	"net/http"
+
+	"github.com/realworld/backend/internal/middleware"
+	"github.com/realworld/backend/internal/models"
+)
+
+// BlockUser hides the target's articles from the current user and removes any
+// follow relationship between the two in either direction
+func (h *Handler) BlockUser(w http.ResponseWriter, r *http.Request) {
+	authUser, ok := middleware.GetUserFromContext(r.Context())
+	if !ok {
+		models.WriteErrorResponse(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	targetUser, ok := h.lookupProfileUser(w, r)
+	if !ok {
+		return
+	}
+
+	if authUser.ID == targetUser.ID {
+		models.WriteErrorResponse(w, http.StatusBadRequest, "Cannot block yourself")
+		return
+	}
+
+	tx, err := h.DB.Begin()
+	if err != nil {
+		h.Logger.Printf("Database error starting block transaction: %v", err)
+		models.WriteErrorResponse(w, http.StatusInternalServerError, "Internal server error")
+		return
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`
+		INSERT OR IGNORE INTO blocks (blocker_id, blocked_id)
+		VALUES (?, ?)
+	`, authUser.ID, targetUser.ID); err != nil {
+		h.Logger.Printf("Database error creating block: %v", err)
+		models.WriteErrorResponse(w, http.StatusInternalServerError, "Internal server error")
+		return
+	}
+
+	if _, err := tx.Exec(`
+		DELETE FROM follows
+		WHERE (follower_id = ? AND following_id = ?) OR (follower_id = ? AND following_id = ?)
+	`, authUser.ID, targetUser.ID, targetUser.ID, authUser.ID); err != nil {
+		h.Logger.Printf("Database error removing follows for block: %v", err)
+		models.WriteErrorResponse(w, http.StatusInternalServerError, "Internal server error")
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		h.Logger.Printf("Database error committing block: %v", err)
+		models.WriteErrorResponse(w, http.StatusInternalServerError, "Internal server error")
+		return
+	}
+
+	profile := targetUser.ToProfile(false)
+	profile.Blocked = true
+	models.WriteJSONResponse(w, http.StatusOK, models.ProfileResponse{Profile: profile})
+}
+
+// UnblockUser removes a block; follows removed by the block are not restored
+func (h *Handler) UnblockUser(w http.ResponseWriter, r *http.Request) {
+	authUser, ok := middleware.GetUserFromContext(r.Context())
+	if !ok {
+		models.WriteErrorResponse(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	targetUser, ok := h.lookupProfileUser(w, r)
+	if !ok {
+		return
+	}
+
+	if _, err := h.DB.Exec(`
+		DELETE FROM blocks
+		WHERE blocker_id = ? AND blocked_id = ?
+	`, authUser.ID, targetUser.ID); err != nil {
+		h.Logger.Printf("Database error removing block: %v", err)
+		models.WriteErrorResponse(w, http.StatusInternalServerError, "Internal server error")
+		return
+	}
+
+	models.WriteJSONResponse(w, http.StatusOK, models.ProfileResponse{
+		Profile: targetUser.ToProfile(false),
+	})
+}
+
+// lookupProfileUser loads the user named in the {username} path value,
+// writing a 404 or 500 response and returning false when it can't
+func (h *Handler) lookupProfileUser(w http.ResponseWriter, r *http.Request) (*models.User, bool) {
+	var user models.User
+	err := h.DB.QueryRow(`
+		SELECT id, username, email, bio, image, created_at, updated_at
+		FROM users WHERE username = ?
+	`, r.PathValue("username")).Scan(
+		&user.ID, &user.Username, &user.Email,
+		&user.Bio, &user.Image, &user.CreatedAt, &user.UpdatedAt,
+	)
+
+	if err == sql.ErrNoRows {
+		models.WriteErrorResponse(w, http.StatusNotFound, "User not found")
+		return nil, false
+	}
+
+	if err != nil {
+		h.Logger.Printf("Database error getting target user: %v", err)
+		models.WriteErrorResponse(w, http.StatusInternalServerError, "Internal server error")
+		return nil, false
+	}
+
+	return &user, true
+}
+
+// isBlockedBetween reports whether either user has blocked the other
+func (h *Handler) isBlockedBetween(userID, otherID int) (bool, error) {
+	var count int
+	err := h.DB.QueryRow(`
+		SELECT COUNT(*) FROM blocks
+		WHERE (blocker_id = ? AND blocked_id = ?) OR (blocker_id = ? AND blocked_id = ?)
+	`, userID, otherID, otherID, userID).Scan(&count)
+	return count > 0, err
+}
+
+// excludeBlockedAuthors returns a SQL condition, taking the viewer's ID as its
+// only argument, that drops rows whose author column the viewer has blocked
+func excludeBlockedAuthors(column string) string {
+	return column + " NOT IN (SELECT blocked_id FROM blocks WHERE blocker_id = ?)"
+}
@@ -0,0 +1,126 @@
+package handlers
+
+import (
+	"database/sql"
+	"net/http"
+	"unicode/utf8"
+
+	"github.com/realworld/backend/internal/middleware"
+	"github.com/realworld/backend/internal/models"
+	"github.com/realworld/backend/internal/utils"
+)
+
+// cloneTitleSuffix marks the title of a cloned article
+const cloneTitleSuffix = " (copy)"
+
+// CloneArticle copies an article's description, body and tags into a new
+// draft owned by the current user. Only the author may clone unless
+// CloneAnyArticle is set.
+func (h *Handler) CloneArticle(w http.ResponseWriter, r *http.Request) {
+	authUser, ok := middleware.GetUserFromContext(r.Context())
+	if !ok {
+		models.WriteErrorResponse(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	source, err := h.getArticleBySlug(r.PathValue("slug"), authUser.ID)
+	if err == sql.ErrNoRows {
+		models.WriteErrorResponse(w, http.StatusNotFound, "Article not found")
+		return
+	}
+	if err != nil {
+		h.Logger.Printf("Database error getting article: %v", err)
+		models.WriteErrorResponse(w, http.StatusInternalServerError, "Internal server error")
+		return
+	}
+
+	if source.AuthorID != authUser.ID && !h.CloneAnyArticle {
+		models.WriteErrorResponse(w, http.StatusForbidden, "You can only clone your own articles")
+		return
+	}
+
+	tx, err := h.DB.Begin()
+	if err != nil {
+		h.Logger.Printf("Database error starting transaction: %v", err)
+		models.WriteErrorResponse(w, http.StatusInternalServerError, "Internal server error")
+		return
+	}
+	defer tx.Rollback()
+
+	if !h.beginArticleCooldown(w, tx, authUser) {
+		return
+	}
+
+	// As in CreateArticle, the slug is chosen inside the transaction and
+	// regenerated should it still collide
+	title := cloneTitle(source.Title)
+	slugs := slugChecker{tx: tx}
+	var slug string
+	var result sql.Result
+	for attempt := 1; ; attempt++ {
+		slug = utils.GenerateUniqueSlug(title, h.SlugStrategy, slugs.exists)
+		if slugs.err != nil {
+			h.Logger.Printf("Database error checking slug: %v", slugs.err)
+			models.WriteErrorResponse(w, http.StatusInternalServerError, "Internal server error")
+			return
+		}
+
+		result, err = tx.Exec(`
+			INSERT INTO articles (slug, title, description, body, author_id, published)
+			VALUES (?, ?, ?, ?, ?, 0)
+		`, slug, title, source.Description, source.Body, authUser.ID)
+		if err == nil {
+			break
+		}
+		if isSlugConflict(err) && attempt < maxSlugAttempts {
+			continue
+		}
+
+		h.Logger.Printf("Database error cloning article: %v", err)
+		models.WriteErrorResponse(w, http.StatusInternalServerError, "Internal server error")
+		return
+	}
+
+	articleID, err := result.LastInsertId()
+	if err != nil {
+		h.Logger.Printf("Error getting article ID: %v", err)
+		models.WriteErrorResponse(w, http.StatusInternalServerError, "Internal server error")
+		return
+	}
+
+	if err := addArticleTags(tx, articleID, source.TagList); err != nil {
+		h.Logger.Printf("Error saving article tags: %v", err)
+		models.WriteErrorResponse(w, http.StatusInternalServerError, "Internal server error")
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		h.Logger.Printf("Error committing transaction: %v", err)
+		models.WriteErrorResponse(w, http.StatusInternalServerError, "Internal server error")
+		return
+	}
+
+	article, err := h.getArticleBySlug(slug, authUser.ID)
+	if err != nil {
+		h.Logger.Printf("Error retrieving cloned article: %v", err)
+		models.WriteErrorResponse(w, http.StatusInternalServerError, "Internal server error")
+		return
+	}
+
+	models.WriteJSONResponse(w, http.StatusCreated, models.ArticleResponse{Article: *article})
+}
+
+// cloneTitle appends cloneTitleSuffix, shortening the original title so the
+// result stays within the title length limit. When the limit can't fit the
+// suffix at all, the title is only shortened.
+func cloneTitle(title string) string {
+	suffix := cloneTitleSuffix
+	if len(suffix) >= models.MaxTitleLength {
+		suffix = ""
+	}
+	for title != "" && len(title)+len(suffix) > models.MaxTitleLength {
+		_, size := utf8.DecodeLastRuneInString(title)
+		title = title[:len(title)-size]
+	}
+	return title + suffix
+}
@@ -0,0 +1,73 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/realworld/backend/internal/models"
+)
+
+func TestCloneTitle(t *testing.T) {
+	defer func(limit int) { models.MaxTitleLength = limit }(models.MaxTitleLength)
+
+	tests := []struct {
+		limit int
+		title string
+		want  string
+	}{
+		{255, "Hello", "Hello (copy)"},
+		{12, "Hello world", "Hello (copy)"},
+		{10, "héllo", "hé (copy)"},
+		{7, "Hello", "Hello"},
+		{3, "Hello", "Hel"},
+		{1, "é", ""},
+	}
+
+	for _, tt := range tests {
+		models.MaxTitleLength = tt.limit
+		if got := cloneTitle(tt.title); got != tt.want {
+			t.Errorf("cloneTitle(%q) with limit %d = %q, want %q", tt.title, tt.limit, got, tt.want)
+		}
+	}
+}
+
+// A clone must not fail when a concurrent writer commits the slug it wants
+func TestCloneArticleSlugTakenByConcurrentCommit(t *testing.T) {
+	h := newTestHandler(t)
+	author := createTestUser(t, h, "writer")
+	source := createTestArticle(t, h, author, "Original")
+
+	ctx := context.Background()
+	conn, err := h.DB.Conn(ctx)
+	if err != nil {
+		t.Fatalf("opening connection: %v", err)
+	}
+	defer conn.Close()
+
+	if _, err := conn.ExecContext(ctx, "BEGIN IMMEDIATE"); err != nil {
+		t.Fatalf("taking write lock: %v", err)
+	}
+	if _, err := conn.ExecContext(ctx, `
+		INSERT INTO articles (slug, title, description, body, author_id)
+		VALUES ('original-copy', 'Original (copy)', 'd', 'b', ?)
+	`, author.ID); err != nil {
+		t.Fatalf("inserting competing article: %v", err)
+	}
+
+	done := make(chan int)
+	go func() {
+		done <- serve("POST /api/articles/{slug}/clone", h.CloneArticle, "POST", "/api/articles/"+source+"/clone", "", author).Code
+	}()
+
+	// Let the clone wait on the lock before the competing commit lands
+	time.Sleep(200 * time.Millisecond)
+	if _, err := conn.ExecContext(ctx, "COMMIT"); err != nil {
+		t.Fatalf("committing competing article: %v", err)
+	}
+
+	if code := <-done; code != http.StatusCreated {
+		t.Errorf("status %d, want %d", code, http.StatusCreated)
+	}
+}
@@ -0,0 +1,488 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/realworld/backend/internal/middleware"
+	"github.com/realworld/backend/internal/models"
+)
+
+// commentSelect loads comments with their author and like state; the two
+// placeholders take the viewer's ID (0 when anonymous)
+const commentSelect = `
+	SELECT
+		c.id, c.body, c.author_id, c.article_id, c.created_at, c.updated_at,
+		u.username, u.bio, u.image,
+		EXISTS(SELECT 1 FROM follows f WHERE f.follower_id = ? AND f.following_id = c.author_id),
+		(SELECT COUNT(*) FROM comment_likes cl WHERE cl.comment_id = c.id),
+		EXISTS(SELECT 1 FROM comment_likes cl WHERE cl.comment_id = c.id AND cl.user_id = ?),
+		c.deleted_at IS NOT NULL
+	FROM comments c
+	JOIN users u ON c.author_id = u.id
+`
+
+// rowScanner is satisfied by both *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanComment(row rowScanner) (*models.Comment, error) {
+	var comment models.Comment
+	err := row.Scan(
+		&comment.ID, &comment.Body, &comment.AuthorID, &comment.ArticleID,
+		&comment.CreatedAt, &comment.UpdatedAt,
+		&comment.Author.Username, &comment.Author.Bio, &comment.Author.Image,
+		&comment.Author.Following, &comment.LikesCount, &comment.Liked,
+		&comment.Deleted,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if comment.Deleted {
+		comment.Body = models.DeletedCommentBody
+	}
+	comment.Author.Image = models.DisplayImage(comment.Author.Image)
+	return &comment, nil
+}
+
+// getComment loads a comment on the given article, returning sql.ErrNoRows
+// when the comment doesn't exist or belongs to another article
+func (h *Handler) getComment(articleID, commentID, viewerID int) (*models.Comment, error) {
+	return scanComment(h.DB.QueryRow(commentSelect+`
+		WHERE c.id = ? AND c.article_id = ?
+	`, viewerID, viewerID, commentID, articleID))
+}
+
+// articleIDBySlug resolves an article slug, writing a 404 or 500 response and
+// returning false when it can't. Drafts only resolve for their author.
+func (h *Handler) articleIDBySlug(w http.ResponseWriter, slug string, viewerID int) (int, bool) {
+	var articleID int
+	err := h.DB.QueryRow("SELECT id FROM articles WHERE slug = ? AND (published = 1 OR author_id = ?)", slug, viewerID).Scan(&articleID)
+
+	if err == sql.ErrNoRows {
+		models.WriteErrorResponse(w, http.StatusNotFound, "Article not found")
+		return 0, false
+	}
+
+	if err != nil {
+		h.Logger.Printf("Database error getting article: %v", err)
+		models.WriteErrorResponse(w, http.StatusInternalServerError, "Internal server error")
+		return 0, false
+	}
+
+	return articleID, true
+}
+
+// commentFromPath loads the comment named by the {slug} and {id} path values,
+// writing a 404 or 500 response and returning false when it can't
+func (h *Handler) commentFromPath(w http.ResponseWriter, r *http.Request, viewerID int) (*models.Comment, bool) {
+	articleID, ok := h.articleIDBySlug(w, r.PathValue("slug"), viewerID)
+	if !ok {
+		return nil, false
+	}
+
+	commentID, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		models.WriteErrorResponse(w, http.StatusNotFound, "Comment not found")
+		return nil, false
+	}
+
+	comment, err := h.getComment(articleID, commentID, viewerID)
+	if err == sql.ErrNoRows {
+		models.WriteErrorResponse(w, http.StatusNotFound, "Comment not found")
+		return nil, false
+	}
+
+	if err != nil {
+		h.Logger.Printf("Database error getting comment: %v", err)
+		models.WriteErrorResponse(w, http.StatusInternalServerError, "Internal server error")
+		return nil, false
+	}
+
+	return comment, true
+}
+
+// Comment handlers - implemented in Phase 1.4
+func (h *Handler) GetComments(w http.ResponseWriter, r *http.Request) {
+	// Get user ID for follow/like status (0 if not authenticated)
+	var userID int
+	if authUser, ok := middleware.GetUserFromContext(r.Context()); ok {
+		userID = authUser.ID
+	}
+
+	articleID, ok := h.articleIDBySlug(w, r.PathValue("slug"), userID)
+	if !ok {
+		return
+	}
+
+	// Oldest first so threads read top to bottom; comments by authors the
+	// viewer has blocked are hidden, while deleted ones stay in place as
+	// tombstones
+	rows, err := h.DB.Query(commentSelect+`
+		WHERE c.article_id = ? AND `+excludeBlockedAuthors("c.author_id")+`
+		ORDER BY c.created_at ASC, c.id ASC
+	`, userID, userID, articleID, userID)
+	if err != nil {
+		h.Logger.Printf("Database error listing comments: %v", err)
+		models.WriteErrorResponse(w, http.StatusInternalServerError, "Internal server error")
+		return
+	}
+	defer rows.Close()
+
+	comments := make([]models.Comment, 0)
+	for rows.Next() {
+		comment, err := scanComment(rows)
+		if err != nil {
+			h.Logger.Printf("Error scanning comment row: %v", err)
+			models.WriteErrorResponse(w, http.StatusInternalServerError, "Internal server error")
+			return
+		}
+		comments = append(comments, *comment)
+	}
+	if err := rows.Err(); err != nil {
+		h.Logger.Printf("Database error listing comments: %v", err)
+		models.WriteErrorResponse(w, http.StatusInternalServerError, "Internal server error")
+		return
+	}
+
+	models.WriteJSONResponse(w, http.StatusOK, models.CommentsResponse{Comments: comments})
+}
+
+func (h *Handler) CreateComment(w http.ResponseWriter, r *http.Request) {
+	// Get user from context (set by auth middleware)
+	authUser, ok := middleware.GetUserFromContext(r.Context())
+	if !ok {
+		models.WriteErrorResponse(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	var req models.CreateCommentRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		models.WriteErrorResponse(w, http.StatusBadRequest, "Invalid JSON format")
+		return
+	}
+
+	if errs := req.Validate(); errs != nil {
+		models.WriteErrorResponse(w, http.StatusUnprocessableEntity, errs)
+		return
+	}
+
+	var articleID int
+	var commentsEnabled bool
+	err := h.DB.QueryRow("SELECT id, comments_enabled FROM articles WHERE slug = ? AND (published = 1 OR author_id = ?)", r.PathValue("slug"), authUser.ID).Scan(&articleID, &commentsEnabled)
+	if err == sql.ErrNoRows {
+		models.WriteErrorResponse(w, http.StatusNotFound, "Article not found")
+		return
+	}
+	if err != nil {
+		h.Logger.Printf("Database error getting article: %v", err)
+		models.WriteErrorResponse(w, http.StatusInternalServerError, "Internal server error")
+		return
+	}
+
+	// Existing comments stay readable; only new ones are refused
+	if !commentsEnabled {
+		models.WriteErrorResponse(w, http.StatusForbidden, "Comments are disabled for this article")
+		return
+	}
+
+	// Deleted comments don't count toward the cap
+	if h.MaxCommentsPerArticle > 0 {
+		var commentCount int
+		err := h.DB.QueryRow("SELECT COUNT(*) FROM comments WHERE article_id = ? AND deleted_at IS NULL", articleID).Scan(&commentCount)
+		if err != nil {
+			h.Logger.Printf("Database error counting comments: %v", err)
+			models.WriteErrorResponse(w, http.StatusInternalServerError, "Internal server error")
+			return
+		}
+		if commentCount >= h.MaxCommentsPerArticle {
+			models.WriteErrorResponse(w, http.StatusForbidden, fmt.Sprintf("This article has reached the limit of %d comments", h.MaxCommentsPerArticle))
+			return
+		}
+	}
+
+	result, err := h.DB.Exec(`
+		INSERT INTO comments (body, author_id, article_id)
+		VALUES (?, ?, ?)
+	`, req.Comment.Body, authUser.ID, articleID)
+	if err != nil {
+		h.Logger.Printf("Database error creating comment: %v", err)
+		models.WriteErrorResponse(w, http.StatusInternalServerError, "Internal server error")
+		return
+	}
+
+	commentID, err := result.LastInsertId()
+	if err != nil {
+		h.Logger.Printf("Error getting comment ID: %v", err)
+		models.WriteErrorResponse(w, http.StatusInternalServerError, "Internal server error")
+		return
+	}
+
+	comment, err := h.getComment(articleID, int(commentID), authUser.ID)
+	if err != nil {
+		h.Logger.Printf("Database error getting created comment: %v", err)
+		models.WriteErrorResponse(w, http.StatusInternalServerError, "Internal server error")
+		return
+	}
+
+	models.WriteJSONResponse(w, http.StatusCreated, models.CommentResponse{Comment: *comment})
+}
+
+func (h *Handler) DeleteComment(w http.ResponseWriter, r *http.Request) {
+	// Get user from context (set by auth middleware)
+	authUser, ok := middleware.GetUserFromContext(r.Context())
+	if !ok {
+		models.WriteErrorResponse(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	comment, ok := h.commentFromPath(w, r, authUser.ID)
+	if !ok {
+		return
+	}
+
+	if comment.AuthorID != authUser.ID {
+		models.WriteErrorResponse(w, http.StatusForbidden, "You can only delete your own comments")
+		return
+	}
+
+	// Soft delete: the row stays as a tombstone, and deleting it again is a no-op
+	if _, err := h.DB.Exec("UPDATE comments SET deleted_at = CURRENT_TIMESTAMP WHERE id = ? AND deleted_at IS NULL", comment.ID); err != nil {
+		h.Logger.Printf("Database error deleting comment: %v", err)
+		models.WriteErrorResponse(w, http.StatusInternalServerError, "Internal server error")
+		return
+	}
+
+	// Return 200 OK with empty response
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte("{}"))
+}
+
+// DeleteComments soft-deletes several comments on one article at once. The
+// article's author may delete any of them, others only their own. Nothing is
+// deleted unless every id is a comment on the article the user may delete.
+func (h *Handler) DeleteComments(w http.ResponseWriter, r *http.Request) {
+	authUser, ok := middleware.GetUserFromContext(r.Context())
+	if !ok {
+		models.WriteErrorResponse(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	articleID, ok := h.articleIDBySlug(w, r.PathValue("slug"), authUser.ID)
+	if !ok {
+		return
+	}
+
+	var req models.DeleteCommentsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		models.WriteErrorResponse(w, http.StatusBadRequest, "Invalid JSON format")
+		return
+	}
+
+	if errs := req.Validate(); errs != nil {
+		models.WriteErrorResponse(w, http.StatusUnprocessableEntity, errs)
+		return
+	}
+
+	tx, err := h.DB.Begin()
+	if err != nil {
+		h.Logger.Printf("Database error starting transaction: %v", err)
+		models.WriteErrorResponse(w, http.StatusInternalServerError, "Internal server error")
+		return
+	}
+	defer tx.Rollback()
+
+	var articleAuthorID int
+	if err := tx.QueryRow("SELECT author_id FROM articles WHERE id = ?", articleID).Scan(&articleAuthorID); err != nil {
+		h.Logger.Printf("Database error getting article author: %v", err)
+		models.WriteErrorResponse(w, http.StatusInternalServerError, "Internal server error")
+		return
+	}
+
+	results := make([]models.CommentDeleteResult, len(req.IDs))
+	notFound, forbidden := false, false
+	for i, id := range req.IDs {
+		results[i].ID = id
+
+		var commentAuthorID int
+		err := tx.QueryRow("SELECT author_id FROM comments WHERE id = ? AND article_id = ?", id, articleID).Scan(&commentAuthorID)
+		if err == sql.ErrNoRows {
+			results[i].Status = models.CommentDeleteStatusNotFound
+			notFound = true
+			continue
+		}
+		if err != nil {
+			h.Logger.Printf("Database error getting comment: %v", err)
+			models.WriteErrorResponse(w, http.StatusInternalServerError, "Internal server error")
+			return
+		}
+
+		if commentAuthorID != authUser.ID && articleAuthorID != authUser.ID {
+			results[i].Status = models.CommentDeleteStatusForbidden
+			forbidden = true
+		}
+	}
+
+	// Report every problem at once, unknown ids taking precedence
+	if notFound || forbidden {
+		for i := range results {
+			if results[i].Status == "" {
+				results[i].Status = models.CommentDeleteStatusSkipped
+			}
+		}
+		status := http.StatusForbidden
+		if notFound {
+			status = http.StatusUnprocessableEntity
+		}
+		models.WriteJSONResponse(w, status, models.DeleteCommentsResponse{Results: results})
+		return
+	}
+
+	// Already deleted comments are left as they are, as with DeleteComment
+	deleted := 0
+	for i, id := range req.IDs {
+		result, err := tx.Exec("UPDATE comments SET deleted_at = CURRENT_TIMESTAMP WHERE id = ? AND deleted_at IS NULL", id)
+		if err != nil {
+			h.Logger.Printf("Database error deleting comment: %v", err)
+			models.WriteErrorResponse(w, http.StatusInternalServerError, "Internal server error")
+			return
+		}
+		if n, _ := result.RowsAffected(); n > 0 {
+			deleted++
+		}
+		results[i].Status = models.CommentDeleteStatusDeleted
+	}
+
+	if err := tx.Commit(); err != nil {
+		h.Logger.Printf("Error committing transaction: %v", err)
+		models.WriteErrorResponse(w, http.StatusInternalServerError, "Internal server error")
+		return
+	}
+
+	models.WriteJSONResponse(w, http.StatusOK, models.DeleteCommentsResponse{
+		Deleted: deleted,
+		Results: results,
+	})
+}
+
+// LikeComment records the current user's like on a comment; liking twice is a no-op
+func (h *Handler) LikeComment(w http.ResponseWriter, r *http.Request) {
+	authUser, ok := middleware.GetUserFromContext(r.Context())
+	if !ok {
+		models.WriteErrorResponse(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	comment, ok := h.commentFromPath(w, r, authUser.ID)
+	if !ok {
+		return
+	}
+
+	if comment.Deleted {
+		models.WriteErrorResponse(w, http.StatusForbidden, "You cannot like a deleted comment")
+		return
+	}
+
+	if comment.AuthorID == authUser.ID && !h.AllowSelfCommentLikes {
+		models.WriteErrorResponse(w, http.StatusForbidden, "You cannot like your own comments")
+		return
+	}
+
+	if !comment.Liked {
+		if _, err := h.DB.Exec(`
+			INSERT OR IGNORE INTO comment_likes (user_id, comment_id)
+			VALUES (?, ?)
+		`, authUser.ID, comment.ID); err != nil {
+			h.Logger.Printf("Database error liking comment: %v", err)
+			models.WriteErrorResponse(w, http.StatusInternalServerError, "Internal server error")
+			return
+		}
+	}
+
+	h.writeComment(w, comment, authUser.ID)
+}
+
+// UnlikeComment removes the current user's like from a comment
+func (h *Handler) UnlikeComment(w http.ResponseWriter, r *http.Request) {
+	authUser, ok := middleware.GetUserFromContext(r.Context())
+	if !ok {
+		models.WriteErrorResponse(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	comment, ok := h.commentFromPath(w, r, authUser.ID)
+	if !ok {
+		return
+	}
+
+	if _, err := h.DB.Exec(`
+		DELETE FROM comment_likes
+		WHERE user_id = ? AND comment_id = ?
+	`, authUser.ID, comment.ID); err != nil {
+		h.Logger.Printf("Database error unliking comment: %v", err)
+		models.WriteErrorResponse(w, http.StatusInternalServerError, "Internal server error")
+		return
+	}
+
+	h.writeComment(w, comment, authUser.ID)
+}
+
+// writeComment reloads a comment so its like state is current and writes it
+func (h *Handler) writeComment(w http.ResponseWriter, comment *models.Comment, viewerID int) {
+	updated, err := h.getComment(comment.ArticleID, comment.ID, viewerID)
+	if err != nil {
+		h.Logger.Printf("Database error reloading comment: %v", err)
+		models.WriteErrorResponse(w, http.StatusInternalServerError, "Internal server error")
+		return
+	}
+
+	models.WriteJSONResponse(w, http.StatusOK, models.CommentResponse{Comment: *updated})
+}
+
+// PurgeComment permanently removes a comment, deleted or not, along with its
+// likes. Only the comment's author or an admin may purge it.
+func (h *Handler) PurgeComment(w http.ResponseWriter, r *http.Request) {
+	authUser, ok := middleware.GetUserFromContext(r.Context())
+	if !ok {
+		models.WriteErrorResponse(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	commentID, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		models.WriteErrorResponse(w, http.StatusNotFound, "Comment not found")
+		return
+	}
+
+	var authorID int
+	err = h.DB.QueryRow("SELECT author_id FROM comments WHERE id = ?", commentID).Scan(&authorID)
+	if err == sql.ErrNoRows {
+		models.WriteErrorResponse(w, http.StatusNotFound, "Comment not found")
+		return
+	}
+	if err != nil {
+		h.Logger.Printf("Database error getting comment: %v", err)
+		models.WriteErrorResponse(w, http.StatusInternalServerError, "Internal server error")
+		return
+	}
+
+	if authorID != authUser.ID && !h.IsAdmin(authUser) {
+		models.WriteErrorResponse(w, http.StatusForbidden, "You can only purge your own comments")
+		return
+	}
+
+	if _, err := h.DB.Exec("DELETE FROM comments WHERE id = ?", commentID); err != nil {
+		h.Logger.Printf("Database error purging comment: %v", err)
+		models.WriteErrorResponse(w, http.StatusInternalServerError, "Internal server error")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte("{}"))
+}
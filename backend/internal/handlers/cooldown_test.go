@@ -0,0 +1,96 @@
+package handlers
+
+import (
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestArticleCooldownCoversEveryCreatePath(t *testing.T) {
+	h := newTestHandler(t)
+	author := createTestUser(t, h, "writer")
+	slug := createTestArticle(t, h, author, "First")
+	h.ArticleCooldown = time.Hour
+
+	tests := []struct {
+		name    string
+		pattern string
+		handler http.HandlerFunc
+		target  string
+		body    string
+	}{
+		{"create", "POST /api/articles", h.CreateArticle, "/api/articles",
+			`{"article":{"title":"Second","description":"d","body":"b"}}`},
+		{"import", "POST /api/articles/import", h.ImportArticles, "/api/articles/import",
+			`{"articles":[{"title":"Imported","description":"d","body":"b"}]}`},
+		{"clone", "POST /api/articles/{slug}/clone", h.CloneArticle, "/api/articles/" + slug + "/clone", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := serve(tt.pattern, tt.handler, "POST", tt.target, tt.body, author)
+			if rec.Code != http.StatusTooManyRequests {
+				t.Fatalf("status %d, want %d: %s", rec.Code, http.StatusTooManyRequests, rec.Body)
+			}
+			if rec.Header().Get("Retry-After") == "" {
+				t.Error("missing Retry-After")
+			}
+		})
+	}
+}
+
+// Backdated imports still start the cooldown
+func TestArticleCooldownAfterBackdatedImport(t *testing.T) {
+	h := newTestHandler(t)
+	author := createTestUser(t, h, "writer")
+	h.ArticleCooldown = time.Hour
+
+	rec := serve("POST /api/articles/import", h.ImportArticles, "POST", "/api/articles/import",
+		`{"articles":[{"title":"Old","description":"d","body":"b","createdAt":"2015-01-01T00:00:00Z"}]}`, author)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("import: status %d: %s", rec.Code, rec.Body)
+	}
+
+	rec = serve("POST /api/articles/import", h.ImportArticles, "POST", "/api/articles/import",
+		`{"articles":[{"title":"Older","description":"d","body":"b","createdAt":"2014-01-01T00:00:00Z"}]}`, author)
+	if rec.Code != http.StatusTooManyRequests {
+		t.Errorf("second import: status %d, want %d", rec.Code, http.StatusTooManyRequests)
+	}
+}
+
+func TestArticleCooldownConcurrentCreates(t *testing.T) {
+	h := newTestHandler(t)
+	author := createTestUser(t, h, "writer")
+	h.ArticleCooldown = time.Hour
+
+	const writers = 6
+	codes := make([]int, writers)
+	start := make(chan struct{})
+	var wg sync.WaitGroup
+	for i := range codes {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			<-start
+			body := `{"article":{"title":"Racing","description":"d","body":"b"}}`
+			codes[i] = serve("POST /api/articles", h.CreateArticle, "POST", "/api/articles", body, author).Code
+		}(i)
+	}
+	close(start)
+	wg.Wait()
+
+	created := 0
+	for i, code := range codes {
+		switch code {
+		case http.StatusCreated:
+			created++
+		case http.StatusTooManyRequests:
+		default:
+			t.Errorf("writer %d: status %d", i, code)
+		}
+	}
+	if created != 1 {
+		t.Errorf("%d creates passed the cooldown, want 1", created)
+	}
+}
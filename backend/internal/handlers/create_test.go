@@ -0,0 +1,125 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/realworld/backend/internal/middleware"
+	"github.com/realworld/backend/internal/models"
+)
+
+func TestCreateArticleConcurrentSameTitle(t *testing.T) {
+	h := newTestHandler(t)
+
+	const writers = 8
+	users := make([]*middleware.User, writers)
+	for i := range users {
+		users[i] = createTestUser(t, h, fmt.Sprintf("writer%d", i))
+	}
+
+	body := `{"article":{"title":"Same Title","description":"d","body":"b","tagList":["go"]}}`
+	codes := make([]int, writers)
+	slugs := make([]string, writers)
+	// Release every writer at once to maximize contention
+	start := make(chan struct{})
+	var wg sync.WaitGroup
+	for i := range users {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			<-start
+			rec := serve("POST /api/articles", h.CreateArticle, "POST", "/api/articles", body, users[i])
+			codes[i] = rec.Code
+			var resp models.ArticleResponse
+			if json.Unmarshal(rec.Body.Bytes(), &resp) == nil {
+				slugs[i] = resp.Article.Slug
+			}
+		}(i)
+	}
+	close(start)
+	wg.Wait()
+
+	seen := make(map[string]bool)
+	for i, code := range codes {
+		if code != http.StatusCreated {
+			t.Errorf("writer %d: status %d, want %d", i, code, http.StatusCreated)
+			continue
+		}
+		if seen[slugs[i]] {
+			t.Errorf("slug %q handed out twice", slugs[i])
+		}
+		seen[slugs[i]] = true
+	}
+}
+
+// A create that reads the slugs while another writer holds the lock must not
+// fail when that writer commits the same slug first
+func TestCreateArticleSlugTakenByConcurrentCommit(t *testing.T) {
+	h := newTestHandler(t)
+	author := createTestUser(t, h, "writer")
+
+	ctx := context.Background()
+	conn, err := h.DB.Conn(ctx)
+	if err != nil {
+		t.Fatalf("opening connection: %v", err)
+	}
+	defer conn.Close()
+
+	if _, err := conn.ExecContext(ctx, "BEGIN IMMEDIATE"); err != nil {
+		t.Fatalf("taking write lock: %v", err)
+	}
+	if _, err := conn.ExecContext(ctx, `
+		INSERT INTO articles (slug, title, description, body, author_id)
+		VALUES ('same-title', 'Same Title', 'd', 'b', ?)
+	`, author.ID); err != nil {
+		t.Fatalf("inserting competing article: %v", err)
+	}
+
+	done := make(chan int)
+	go func() {
+		body := `{"article":{"title":"Same Title","description":"d","body":"b"}}`
+		done <- serve("POST /api/articles", h.CreateArticle, "POST", "/api/articles", body, author).Code
+	}()
+
+	// Let the create read the slugs before the competing commit lands
+	time.Sleep(200 * time.Millisecond)
+	if _, err := conn.ExecContext(ctx, "COMMIT"); err != nil {
+		t.Fatalf("committing competing article: %v", err)
+	}
+
+	if code := <-done; code != http.StatusCreated {
+		t.Errorf("status %d, want %d", code, http.StatusCreated)
+	}
+}
+
+func TestCreateArticleTimestampsAreUTC(t *testing.T) {
+	h := newTestHandler(t)
+	author := createTestUser(t, h, "writer")
+
+	rec := serve("POST /api/articles", h.CreateArticle, "POST", "/api/articles",
+		`{"article":{"title":"Timely","description":"d","body":"b"}}`, author)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("status %d: %s", rec.Code, rec.Body)
+	}
+
+	var resp struct {
+		Article struct {
+			CreatedAt string `json:"createdAt"`
+			UpdatedAt string `json:"updatedAt"`
+		} `json:"article"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decoding response: %v", err)
+	}
+	for name, value := range map[string]string{"createdAt": resp.Article.CreatedAt, "updatedAt": resp.Article.UpdatedAt} {
+		if _, err := time.Parse(time.RFC3339, value); err != nil || !strings.HasSuffix(value, "Z") {
+			t.Errorf("%s = %q, want an RFC3339 UTC time ending in Z", name, value)
+		}
+	}
+}
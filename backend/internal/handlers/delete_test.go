@@ -0,0 +1,46 @@
+package handlers
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestDeleteArticleRemovesComments(t *testing.T) {
+	h := newTestHandler(t)
+	author := createTestUser(t, h, "author")
+	reader := createTestUser(t, h, "reader")
+
+	slug := createTestArticle(t, h, author, "Doomed")
+	kept := createTestArticle(t, h, author, "Kept")
+	for _, s := range []string{slug, slug, kept} {
+		rec := serve("POST /api/articles/{slug}/comments", h.CreateComment, "POST", "/api/articles/"+s+"/comments",
+			`{"comment":{"body":"Nice"}}`, reader)
+		if rec.Code != http.StatusCreated {
+			t.Fatalf("commenting on %s: status %d: %s", s, rec.Code, rec.Body)
+		}
+	}
+
+	var doomedID, keptID int
+	h.DB.QueryRow("SELECT id FROM articles WHERE slug = ?", slug).Scan(&doomedID)
+	h.DB.QueryRow("SELECT id FROM articles WHERE slug = ?", kept).Scan(&keptID)
+
+	rec := serve("DELETE /api/articles/{slug}", h.DeleteArticle, "DELETE", "/api/articles/"+slug, "", author)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("deleting article: status %d: %s", rec.Code, rec.Body)
+	}
+
+	countComments := func(articleID int) int {
+		t.Helper()
+		var n int
+		if err := h.DB.QueryRow("SELECT COUNT(*) FROM comments WHERE article_id = ?", articleID).Scan(&n); err != nil {
+			t.Fatalf("counting comments: %v", err)
+		}
+		return n
+	}
+	if n := countComments(doomedID); n != 0 {
+		t.Errorf("%d comments left behind by the deleted article", n)
+	}
+	if n := countComments(keptID); n != 1 {
+		t.Errorf("other article has %d comments, want 1", n)
+	}
+}
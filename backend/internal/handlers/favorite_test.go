@@ -0,0 +1,70 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/realworld/backend/internal/models"
+)
+
+func TestFavoriteArticleTwice(t *testing.T) {
+	h := newTestHandler(t)
+	author := createTestUser(t, h, "author")
+	reader := createTestUser(t, h, "reader")
+	slug := createTestArticle(t, h, author, "Popular")
+
+	for i := 1; i <= 2; i++ {
+		rec := serve("POST /api/articles/{slug}/favorite", h.FavoriteArticle, "POST", "/api/articles/"+slug+"/favorite", "", reader)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("favorite %d: status %d: %s", i, rec.Code, rec.Body)
+		}
+
+		var resp models.ArticleResponse
+		if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("favorite %d: %v", i, err)
+		}
+		if !resp.Article.Favorited || resp.Article.FavoritesCount != 1 {
+			t.Errorf("favorite %d: favorited %t, favoritesCount %d, want true and 1",
+				i, resp.Article.Favorited, resp.Article.FavoritesCount)
+		}
+	}
+
+	var rows int
+	if err := h.DB.QueryRow(`
+		SELECT COUNT(*) FROM favorites
+		WHERE user_id = ? AND article_id = (SELECT id FROM articles WHERE slug = ?)
+	`, reader.ID, slug).Scan(&rows); err != nil {
+		t.Fatalf("counting favorites: %v", err)
+	}
+	if rows != 1 {
+		t.Errorf("%d favorite rows, want 1", rows)
+	}
+}
+
+func TestUnfavoriteArticleTwice(t *testing.T) {
+	h := newTestHandler(t)
+	author := createTestUser(t, h, "author")
+	reader := createTestUser(t, h, "reader")
+	slug := createTestArticle(t, h, author, "Fleeting")
+
+	if rec := serve("POST /api/articles/{slug}/favorite", h.FavoriteArticle, "POST", "/api/articles/"+slug+"/favorite", "", reader); rec.Code != http.StatusOK {
+		t.Fatalf("favorite: status %d: %s", rec.Code, rec.Body)
+	}
+
+	for i := 1; i <= 2; i++ {
+		rec := serve("DELETE /api/articles/{slug}/favorite", h.UnfavoriteArticle, "DELETE", "/api/articles/"+slug+"/favorite", "", reader)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("unfavorite %d: status %d: %s", i, rec.Code, rec.Body)
+		}
+
+		var resp models.ArticleResponse
+		if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("unfavorite %d: %v", i, err)
+		}
+		if resp.Article.Favorited || resp.Article.FavoritesCount != 0 {
+			t.Errorf("unfavorite %d: favorited %t, favoritesCount %d, want false and 0",
+				i, resp.Article.Favorited, resp.Article.FavoritesCount)
+		}
+	}
+}
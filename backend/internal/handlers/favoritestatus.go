@@ -0,0 +1,86 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/realworld/backend/internal/middleware"
+	"github.com/realworld/backend/internal/models"
+)
+
+// maxFavoriteStatusSlugs caps the articles one favorited-status call can ask about
+const maxFavoriteStatusSlugs = 100
+
+// GetFavoritedStatus reports, for each requested slug, whether the current
+// user has favorited that article, so a list of cards can be hydrated in one
+// call. ?slugs= may be repeated or comma-separated; unknown slugs are left out.
+func (h *Handler) GetFavoritedStatus(w http.ResponseWriter, r *http.Request) {
+	authUser, ok := middleware.GetUserFromContext(r.Context())
+	if !ok {
+		models.WriteErrorResponse(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	var slugs []string
+	seen := make(map[string]bool)
+	for _, value := range r.URL.Query()["slugs"] {
+		for _, slug := range strings.Split(value, ",") {
+			slug = strings.TrimSpace(slug)
+			if slug == "" || seen[slug] {
+				continue
+			}
+			seen[slug] = true
+			slugs = append(slugs, slug)
+		}
+	}
+	if len(slugs) > maxFavoriteStatusSlugs {
+		models.WriteErrorResponse(w, http.StatusUnprocessableEntity, models.ValidationErrors{
+			{Field: "slugs", Message: "must list at most " + strconv.Itoa(maxFavoriteStatusSlugs) + " slugs"},
+		})
+		return
+	}
+
+	status := make(map[string]bool, len(slugs))
+	if len(slugs) == 0 {
+		models.WriteJSONResponse(w, http.StatusOK, status)
+		return
+	}
+
+	args := []interface{}{authUser.ID, authUser.ID}
+	for _, slug := range slugs {
+		args = append(args, slug)
+	}
+
+	// Drafts only exist for their author
+	rows, err := h.DB.Query(`
+		SELECT a.slug, EXISTS(SELECT 1 FROM favorites f WHERE f.article_id = a.id AND f.user_id = ?)
+		FROM articles a
+		WHERE (a.published = 1 OR a.author_id = ?)
+		  AND a.slug IN (`+strings.TrimSuffix(strings.Repeat("?,", len(slugs)), ",")+`)
+	`, args...)
+	if err != nil {
+		h.Logger.Printf("Database error getting favorited status: %v", err)
+		models.WriteErrorResponse(w, http.StatusInternalServerError, "Internal server error")
+		return
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var slug string
+		var favorited bool
+		if err := rows.Scan(&slug, &favorited); err != nil {
+			h.Logger.Printf("Error scanning favorited status row: %v", err)
+			models.WriteErrorResponse(w, http.StatusInternalServerError, "Internal server error")
+			return
+		}
+		status[slug] = favorited
+	}
+	if err := rows.Err(); err != nil {
+		h.Logger.Printf("Database error getting favorited status: %v", err)
+		models.WriteErrorResponse(w, http.StatusInternalServerError, "Internal server error")
+		return
+	}
+
+	models.WriteJSONResponse(w, http.StatusOK, status)
+}
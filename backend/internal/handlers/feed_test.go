@@ -0,0 +1,71 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/realworld/backend/internal/models"
+)
+
+func TestFeedWithLegacySelfFollow(t *testing.T) {
+	h := newTestHandler(t)
+	reader := createTestUser(t, h, "reader")
+	author := createTestUser(t, h, "author")
+
+	want := map[string]bool{
+		createTestArticle(t, h, author, "Followed One"): true,
+		createTestArticle(t, h, author, "Followed Two"): true,
+		createTestArticle(t, h, reader, "My Own"):       true,
+	}
+	createTestArticle(t, h, createTestUser(t, h, "stranger"), "Not Followed")
+
+	if _, err := h.DB.Exec("INSERT INTO follows (follower_id, following_id) VALUES (?, ?)", reader.ID, author.ID); err != nil {
+		t.Fatalf("following author: %v", err)
+	}
+
+	// Databases from before no_self_follow may still hold a self-follow
+	ctx := context.Background()
+	conn, err := h.DB.Conn(ctx)
+	if err != nil {
+		t.Fatalf("opening connection: %v", err)
+	}
+	defer conn.Close()
+	if _, err := conn.ExecContext(ctx, "PRAGMA ignore_check_constraints = ON"); err != nil {
+		t.Fatalf("disabling check constraints: %v", err)
+	}
+	if _, err := conn.ExecContext(ctx, "INSERT INTO follows (follower_id, following_id) VALUES (?, ?)", reader.ID, reader.ID); err != nil {
+		t.Fatalf("seeding self-follow: %v", err)
+	}
+	if _, err := conn.ExecContext(ctx, "PRAGMA ignore_check_constraints = OFF"); err != nil {
+		t.Fatalf("enabling check constraints: %v", err)
+	}
+
+	rec := serve("GET /api/articles/feed", h.GetFeed, "GET", "/api/articles/feed?includeSelf=true", "", reader)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status %d: %s", rec.Code, rec.Body)
+	}
+
+	var resp models.ArticlesResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decoding response: %v", err)
+	}
+
+	seen := make(map[string]bool)
+	for _, article := range resp.Articles {
+		if seen[article.Slug] {
+			t.Errorf("%s appears more than once", article.Slug)
+		}
+		seen[article.Slug] = true
+		if !want[article.Slug] {
+			t.Errorf("%s is not in the reader's feed", article.Slug)
+		}
+	}
+	if len(seen) != len(want) {
+		t.Errorf("feed holds %d articles, want %d", len(seen), len(want))
+	}
+	if resp.ArticlesCount != len(want) {
+		t.Errorf("articlesCount %d, want %d", resp.ArticlesCount, len(want))
+	}
+}
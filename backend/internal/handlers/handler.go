@@ -3,28 +3,143 @@ package handlers
 import (
 	"database/sql"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"log"
 	"net/http"
+	"net/url"
 	"strconv"
 	"strings"
+	"time"
 
+	"github.com/mattn/go-sqlite3"
 	"github.com/realworld/backend/internal/middleware"
 	"github.com/realworld/backend/internal/models"
+	"github.com/realworld/backend/internal/storage"
 	"github.com/realworld/backend/internal/utils"
 )
 
 // Handler holds dependencies for HTTP handlers
 type Handler struct {
-	DB        *sql.DB
-	JWTSecret string
-	Logger    *log.Logger
+	DB           *sql.DB
+	JWTSecret    string
+	Logger       *log.Logger
+	SlugStrategy utils.SlugStrategy
+
+	// TwoFactorEnabled turns on TOTP enrollment and enforcement at login
+	TwoFactorEnabled bool
+	// TwoFactorKey is the AES-256 key used to encrypt stored TOTP secrets
+	TwoFactorKey []byte
+
+	// Storage holds uploaded avatar images
+	Storage storage.Storage
+	// AvatarMaxBytes caps the size of an uploaded avatar
+	AvatarMaxBytes int64
+	// AvatarMaxDimension caps an uploaded avatar's width and height in pixels
+	AvatarMaxDimension int
+	// PublicBaseURL is the external scheme and host used in absolute links
+	// when no trusted proxy supplies them; empty means use the request's own
+	PublicBaseURL string
+
+	// RegistrationEnabled allows open signup; when false, Register requires an invite code
+	RegistrationEnabled bool
+	// Admins holds the lower-cased usernames allowed to use admin endpoints
+	Admins map[string]bool
+
+	// AllowSelfCommentLikes lets users like their own comments
+	AllowSelfCommentLikes bool
+	// MaxFollowsPerDay caps the users one user can follow per UTC day;
+	// unfollowing doesn't refund it and zero means no limit
+	MaxFollowsPerDay int
+	// LenientSelfFollow answers an attempt to follow oneself with the
+	// unchanged profile instead of a 400
+	LenientSelfFollow bool
+	// ArticleCooldown is the least time between one user's article creations;
+	// admins are exempt and zero disables it
+	ArticleCooldown time.Duration
+	// CloneAnyArticle lets users clone other authors' published articles,
+	// not just their own
+	CloneAnyArticle bool
+	// MaxCommentsPerArticle caps the non-deleted comments on an article;
+	// zero means no limit
+	MaxCommentsPerArticle int
+	// MaxPageOffset caps the offset list endpoints accept, as SQLite reads
+	// and discards every skipped row; zero means no limit
+	MaxPageOffset int
+
+	// Build identifies the running binary in health checks
+	Build BuildInfo
+	// StartTime is when the process started, for reporting uptime
+	StartTime time.Time
+
+	// SlowQueryThreshold enables logging of hot-path queries at least this
+	// slow; zero disables it
+	SlowQueryThreshold time.Duration
+	// SlowQueryLogArgs includes parameter values in slow query logs
+	SlowQueryLogArgs bool
+
+	// TokenCutoffs caches when each user's tokens start being valid, so
+	// tokens issued before a password change are rejected
+	TokenCutoffs *middleware.TokenCutoffCache
+	// Auth configures the authentication middleware for every protected route
+	Auth middleware.AuthConfig
+
+	// StatsCacheTTL is how long GET /api/stats serves cached totals
+	StatsCacheTTL time.Duration
+	stats         statsCache
+
+	// TagsCacheTTL is how long GET /api/tags serves its cached list; article
+	// writes invalidate it sooner
+	TagsCacheTTL time.Duration
+	tags         tagsCache
+
+	// ArticleListCacheTTL is how long anonymous GET /api/articles responses
+	// are served from memory; article writes invalidate them sooner and zero
+	// disables the cache
+	ArticleListCacheTTL time.Duration
+	articleLists        articleListCache
+
+	// TagSuggestMinPrefix is the shortest prefix GET /api/tags/suggest answers
+	TagSuggestMinPrefix int
+	// TagSuggestMaxResults caps the tags GET /api/tags/suggest returns
+	TagSuggestMaxResults int
+
+	// TrendingDays is the window GET /api/tags/trending counts favorites in
+	TrendingDays int
+	// TrendingMinFavorites is the fewest recent favorites a trending tag needs
+	TrendingMinFavorites int
+	// TrendingCacheTTL is how long the trending ranking is reused
+	TrendingCacheTTL time.Duration
+	trending         trendingCache
+
+	// EmptyListSuggestions is how many popular articles ListArticles offers
+	// when ?suggestOnEmpty=true matches nothing; zero disables suggestions
+	EmptyListSuggestions int
+
+	// RecommendationsPerTag caps how many ?discover=true recommendations
+	// share any one tag; zero leaves them uncapped
+	RecommendationsPerTag int
+
+	// DefaultArticleSort orders ListArticles when no ?sort= is given
+	DefaultArticleSort string
+}
+
+// BuildInfo describes the deployed build, injected at link time
+type BuildInfo struct {
+	Version   string
+	Commit    string
+	BuildTime string
 }
 
 // Health handler for health checks
 func (h *Handler) Health(w http.ResponseWriter, r *http.Request) {
-	models.WriteJSONResponse(w, http.StatusOK, map[string]string{
-		"status": "ok",
-		"message": "RealWorld API is running",
+	models.WriteJSONResponse(w, http.StatusOK, models.HealthResponse{
+		Status:    "ok",
+		Message:   "RealWorld API is running",
+		Version:   h.Build.Version,
+		Commit:    h.Build.Commit,
+		BuildTime: h.Build.BuildTime,
+		Uptime:    time.Since(h.StartTime).Round(time.Second).String(),
 	})
 }
 
@@ -36,6 +151,12 @@ func (h *Handler) Register(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// A closed instance only accepts signups carrying an invite code
+	if !h.RegistrationEnabled && req.User.InviteCode == "" {
+		models.WriteErrorResponse(w, http.StatusForbidden, "Registration is closed; an invite code is required")
+		return
+	}
+
 	// Validate request
 	if validationErrors := req.Validate(); len(validationErrors) > 0 {
 		models.WriteErrorResponse(w, http.StatusUnprocessableEntity, validationErrors)
@@ -81,8 +202,17 @@ func (h *Handler) Register(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Begin transaction so an invite code is only consumed by a successful signup
+	tx, err := h.DB.Begin()
+	if err != nil {
+		h.Logger.Printf("Database error starting transaction: %v", err)
+		models.WriteErrorResponse(w, http.StatusInternalServerError, "Internal server error")
+		return
+	}
+	defer tx.Rollback()
+
 	// Insert user into database
-	result, err := h.DB.Exec(`
+	result, err := tx.Exec(`
 		INSERT INTO users (username, email, password_hash, bio, image) 
 		VALUES (?, ?, ?, '', '')
 	`, req.User.Username, req.User.Email, hashedPassword)
@@ -101,8 +231,36 @@ func (h *Handler) Register(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// A supplied invite code must be valid even while registration is open,
+	// so every code records the account it created
+	if req.User.InviteCode != "" {
+		consumed, err := tx.Exec(`
+			UPDATE invite_codes SET used_at = CURRENT_TIMESTAMP, used_by = ?
+			WHERE code = ? AND used_at IS NULL
+				AND (expires_at IS NULL OR expires_at > CURRENT_TIMESTAMP)
+		`, userID, req.User.InviteCode)
+		if err != nil {
+			h.Logger.Printf("Database error consuming invite code: %v", err)
+			models.WriteErrorResponse(w, http.StatusInternalServerError, "Internal server error")
+			return
+		}
+
+		if n, _ := consumed.RowsAffected(); n == 0 {
+			models.WriteErrorResponse(w, http.StatusUnprocessableEntity, models.ValidationErrors{
+				{Field: "inviteCode", Message: "is invalid, expired or already used"},
+			})
+			return
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		h.Logger.Printf("Database error committing registration: %v", err)
+		models.WriteErrorResponse(w, http.StatusInternalServerError, "Internal server error")
+		return
+	}
+
 	// Generate JWT token
-	token, err := utils.GenerateToken(int(userID), req.User.Username, h.JWTSecret)
+	token, err := h.issueToken("", int(userID), req.User.Username)
 	if err != nil {
 		h.Logger.Printf("Token generation error: %v", err)
 		models.WriteErrorResponse(w, http.StatusInternalServerError, "Internal server error")
@@ -125,6 +283,9 @@ func (h *Handler) Register(w http.ResponseWriter, r *http.Request) {
 	models.WriteJSONResponse(w, http.StatusCreated, response)
 }
 
+// Login authenticates a user by email and password. Missing or malformed input
+// is a 422 with field errors; well-formed but wrong credentials are always a
+// generic 401 so the response never reveals whether the email exists.
 func (h *Handler) Login(w http.ResponseWriter, r *http.Request) {
 	var req models.LoginRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
@@ -150,6 +311,7 @@ func (h *Handler) Login(w http.ResponseWriter, r *http.Request) {
 	)
 
 	if err == sql.ErrNoRows {
+		h.Auth.LogFailure(r, "wrong credentials")
 		models.WriteErrorResponse(w, http.StatusUnauthorized, "Invalid email or password")
 		return
 	}
@@ -160,20 +322,42 @@ func (h *Handler) Login(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Check password
+	// Check password (same message as an unknown email)
 	if err := utils.CheckPassword(req.User.Password, passwordHash); err != nil {
+		h.Auth.LogFailure(r, "wrong credentials")
 		models.WriteErrorResponse(w, http.StatusUnauthorized, "Invalid email or password")
 		return
 	}
 
+	// Check second factor for users who enrolled in 2FA
+	reason, err := h.checkSecondFactor(user.ID, req.User.TOTPCode, req.User.RecoveryCode)
+	if err != nil {
+		h.Logger.Printf("Error checking second factor: %v", err)
+		models.WriteErrorResponse(w, http.StatusInternalServerError, "Internal server error")
+		return
+	}
+
+	if reason != "" {
+		h.Auth.LogFailure(r, "wrong second factor")
+		models.WriteErrorResponse(w, http.StatusUnauthorized, reason)
+		return
+	}
+
 	// Generate JWT token
-	token, err := utils.GenerateToken(user.ID, user.Username, h.JWTSecret)
+	token, err := h.issueToken("", user.ID, user.Username)
 	if err != nil {
 		h.Logger.Printf("Token generation error: %v", err)
 		models.WriteErrorResponse(w, http.StatusInternalServerError, "Internal server error")
 		return
 	}
 
+	// A failure to record the login is logged but doesn't fail it
+	if _, err := h.DB.Exec(`
+		UPDATE users SET last_login_at = CURRENT_TIMESTAMP, last_login_ip = ? WHERE id = ?
+	`, middleware.ClientIP(r), user.ID); err != nil {
+		h.Logger.Printf("Database error recording login: %v", err)
+	}
+
 	// Create user response
 	response := models.UserResponse{
 		User: user.ToUserData(token),
@@ -192,12 +376,13 @@ func (h *Handler) GetCurrentUser(w http.ResponseWriter, r *http.Request) {
 
 	// Get full user details from database
 	var user models.User
+	var lastLoginAt models.Timestamp
 	err := h.DB.QueryRow(`
-		SELECT id, username, email, bio, image, created_at, updated_at 
+		SELECT id, username, email, bio, image, created_at, updated_at, last_login_at
 		FROM users WHERE id = ?
 	`, authUser.ID).Scan(
 		&user.ID, &user.Username, &user.Email, 
-		&user.Bio, &user.Image, &user.CreatedAt, &user.UpdatedAt,
+		&user.Bio, &user.Image, &user.CreatedAt, &user.UpdatedAt, &lastLoginAt,
 	)
 
 	if err == sql.ErrNoRows {
@@ -212,7 +397,7 @@ func (h *Handler) GetCurrentUser(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Generate new token to refresh expiration
-	token, err := utils.GenerateToken(user.ID, user.Username, h.JWTSecret)
+	token, err := h.refreshToken(authUser, user.ID, user.Username)
 	if err != nil {
 		h.Logger.Printf("Token generation error: %v", err)
 		models.WriteErrorResponse(w, http.StatusInternalServerError, "Internal server error")
@@ -223,10 +408,56 @@ func (h *Handler) GetCurrentUser(w http.ResponseWriter, r *http.Request) {
 	response := models.UserResponse{
 		User: user.ToUserData(token),
 	}
+	if !lastLoginAt.IsZero() {
+		response.User.LastLoginAt = &lastLoginAt
+	}
 
 	models.WriteJSONResponse(w, http.StatusOK, response)
 }
 
+// RefreshUserToken exchanges a valid JWT for a fresh one with a new expiry,
+// iat and jti in the same session, so long-lived sessions needn't log in
+// again. The old token stays valid until it expires or is revoked.
+func (h *Handler) RefreshUserToken(w http.ResponseWriter, r *http.Request) {
+	authUser, ok := middleware.GetUserFromContext(r.Context())
+	if !ok {
+		models.WriteErrorResponse(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	if authUser.APIKeyID != 0 {
+		models.WriteErrorResponse(w, http.StatusForbidden, "API keys have no token to refresh")
+		return
+	}
+
+	var user models.User
+	err := h.DB.QueryRow(`
+		SELECT id, username, email, bio, image, created_at, updated_at
+		FROM users WHERE id = ?
+	`, authUser.ID).Scan(
+		&user.ID, &user.Username, &user.Email,
+		&user.Bio, &user.Image, &user.CreatedAt, &user.UpdatedAt,
+	)
+	if err == sql.ErrNoRows {
+		models.WriteErrorResponse(w, http.StatusNotFound, "User not found")
+		return
+	}
+	if err != nil {
+		h.Logger.Printf("Database error refreshing token: %v", err)
+		models.WriteErrorResponse(w, http.StatusInternalServerError, "Internal server error")
+		return
+	}
+
+	token, err := h.issueToken(authUser.SessionID, user.ID, user.Username)
+	if err != nil {
+		h.Logger.Printf("Token generation error: %v", err)
+		models.WriteErrorResponse(w, http.StatusInternalServerError, "Internal server error")
+		return
+	}
+
+	models.WriteJSONResponse(w, http.StatusOK, models.UserResponse{User: user.ToUserData(token)})
+}
+
 func (h *Handler) UpdateUser(w http.ResponseWriter, r *http.Request) {
 	// Get user from context (set by auth middleware)
 	authUser, ok := middleware.GetUserFromContext(r.Context())
@@ -294,15 +525,25 @@ func (h *Handler) UpdateUser(w http.ResponseWriter, r *http.Request) {
 	if req.User.Email != "" {
 		updateValues["email"] = req.User.Email
 	}
-	if req.User.Bio != "" || req.User.Bio == "" { // Allow empty bio
-		updateValues["bio"] = req.User.Bio
+	// An absent bio or image is unchanged; null or "" clears it
+	if req.User.Bio.Present {
+		updateValues["bio"] = req.User.Bio.Value
 	}
-	if req.User.Image != "" || req.User.Image == "" { // Allow empty image
-		updateValues["image"] = req.User.Image
+	if req.User.Image.Present {
+		// A client echoing back the default avatar keeps the stored image empty
+		if models.DefaultAvatarURL != "" && req.User.Image.Value == models.DefaultAvatarURL {
+			req.User.Image.Value = ""
+		}
+		updateValues["image"] = req.User.Image.Value
 	}
 
 	// Handle password update
 	if req.User.Password != "" {
+		if authUser.APIKeyID != 0 {
+			models.WriteErrorResponse(w, http.StatusForbidden, "The password can't be changed with an API key")
+			return
+		}
+
 		hashedPassword, err := utils.HashPassword(req.User.Password)
 		if err != nil {
 			h.Logger.Printf("Password hashing error: %v", err)
@@ -310,6 +551,9 @@ func (h *Handler) UpdateUser(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 		updateValues["password_hash"] = hashedPassword
+		// Revoke tokens issued before now. The token returned below is issued
+		// afterwards, and iat has second precision, so it stays valid.
+		updateValues["tokens_valid_after"] = time.Now().UTC().Format(sqliteTimeFormat)
 	}
 
 	// Build dynamic update query
@@ -331,14 +575,63 @@ func (h *Handler) UpdateUser(w http.ResponseWriter, r *http.Request) {
 	query += " WHERE id = ?"
 	args = append(args, authUser.ID)
 
+	// Begin transaction so the update and its audit trail land together
+	tx, err := h.DB.Begin()
+	if err != nil {
+		h.Logger.Printf("Database error starting transaction: %v", err)
+		models.WriteErrorResponse(w, http.StatusInternalServerError, "Internal server error")
+		return
+	}
+	defer tx.Rollback()
+
 	// Execute update
-	_, err = h.DB.Exec(query, args...)
+	_, err = tx.Exec(query, args...)
 	if err != nil {
 		h.Logger.Printf("Database error updating user: %v", err)
 		models.WriteErrorResponse(w, http.StatusInternalServerError, "Internal server error")
 		return
 	}
 
+	// Record email and username changes for the security log
+	changes := []struct{ field, oldValue, newValue string }{
+		{"email", currentUser.Email, req.User.Email},
+		{"username", currentUser.Username, req.User.Username},
+	}
+	for _, change := range changes {
+		if change.newValue == "" || change.newValue == change.oldValue {
+			continue
+		}
+
+		_, err = tx.Exec(`
+			INSERT INTO user_changes (user_id, field, old_value, new_value) 
+			VALUES (?, ?, ?, ?)
+		`, authUser.ID, change.field, change.oldValue, change.newValue)
+		if err != nil {
+			h.Logger.Printf("Database error recording user change: %v", err)
+			models.WriteErrorResponse(w, http.StatusInternalServerError, "Internal server error")
+			return
+		}
+	}
+
+	// Commit transaction
+	if err = tx.Commit(); err != nil {
+		h.Logger.Printf("Error committing transaction: %v", err)
+		models.WriteErrorResponse(w, http.StatusInternalServerError, "Internal server error")
+		return
+	}
+	if req.User.Password != "" {
+		h.TokenCutoffs.Invalidate(authUser.ID)
+
+		// The cutoff already rejects the other sessions' tokens; revoking
+		// them too keeps them off the sessions list
+		if _, err := h.DB.Exec(`
+			UPDATE sessions SET revoked_at = CURRENT_TIMESTAMP
+			WHERE user_id = ? AND id != ? AND revoked_at IS NULL
+		`, authUser.ID, authUser.SessionID); err != nil {
+			h.Logger.Printf("Database error revoking sessions: %v", err)
+		}
+	}
+
 	// Get updated user data
 	var updatedUser models.User
 	err = h.DB.QueryRow(`
@@ -357,7 +650,7 @@ func (h *Handler) UpdateUser(w http.ResponseWriter, r *http.Request) {
 
 	// Generate new token with updated username if needed
 	username := updatedUser.Username
-	token, err := utils.GenerateToken(updatedUser.ID, username, h.JWTSecret)
+	token, err := h.refreshToken(authUser, updatedUser.ID, username)
 	if err != nil {
 		h.Logger.Printf("Token generation error: %v", err)
 		models.WriteErrorResponse(w, http.StatusInternalServerError, "Internal server error")
@@ -372,6 +665,118 @@ func (h *Handler) UpdateUser(w http.ResponseWriter, r *http.Request) {
 	models.WriteJSONResponse(w, http.StatusOK, response)
 }
 
+// TokenValidAfter returns the time before which the user's tokens are
+// rejected, or the zero time when no cutoff is set. Unknown users return
+// sql.ErrNoRows, so tokens of deleted accounts are rejected too.
+func (h *Handler) TokenValidAfter(userID int) (time.Time, error) {
+	var validAfter sql.NullTime
+	err := h.DB.QueryRow("SELECT tokens_valid_after FROM users WHERE id = ?", userID).Scan(&validAfter)
+	if err != nil {
+		if err != sql.ErrNoRows {
+			h.Logger.Printf("Database error getting token cutoff: %v", err)
+		}
+		return time.Time{}, err
+	}
+	return validAfter.Time, nil
+}
+
+// GetSecurityLog returns the authenticated user's recent email and username changes
+func (h *Handler) GetSecurityLog(w http.ResponseWriter, r *http.Request) {
+	// Get user from context (set by auth middleware)
+	authUser, ok := middleware.GetUserFromContext(r.Context())
+	if !ok {
+		models.WriteErrorResponse(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	limit, offset, errs := parsePagination(r.URL.Query())
+	if errs != nil {
+		models.WriteErrorResponse(w, http.StatusUnprocessableEntity, errs)
+		return
+	}
+	if h.offsetTooDeep(w, offset) {
+		return
+	}
+
+	rows, err := h.DB.Query(`
+		SELECT field, old_value, new_value, changed_at 
+		FROM user_changes 
+		WHERE user_id = ?
+		ORDER BY changed_at DESC, id DESC
+		LIMIT ? OFFSET ?
+	`, authUser.ID, limit, offset)
+	if err != nil {
+		h.Logger.Printf("Database error getting security log: %v", err)
+		models.WriteErrorResponse(w, http.StatusInternalServerError, "Internal server error")
+		return
+	}
+	defer rows.Close()
+
+	changes := make([]models.UserChange, 0)
+	for rows.Next() {
+		var change models.UserChange
+		if err := rows.Scan(&change.Field, &change.OldValue, &change.NewValue, &change.ChangedAt); err != nil {
+			h.Logger.Printf("Error scanning security log row: %v", err)
+			models.WriteErrorResponse(w, http.StatusInternalServerError, "Internal server error")
+			return
+		}
+		changes = append(changes, change)
+	}
+
+	response := models.SecurityLogResponse{
+		Changes: changes,
+	}
+
+	models.WriteJSONResponse(w, http.StatusOK, response)
+}
+
+// CheckAvailability reports whether the username and/or email in the query
+// are unused. Lookups ignore case and surrounding whitespace, matching the
+// NOCASE uniqueness constraints on the users table.
+func (h *Handler) CheckAvailability(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	username := strings.TrimSpace(query.Get("username"))
+	email := strings.TrimSpace(query.Get("email"))
+
+	if username == "" && email == "" {
+		models.WriteErrorResponse(w, http.StatusUnprocessableEntity, models.ValidationErrors{
+			{Field: "query", Message: "username or email is required"},
+		})
+		return
+	}
+
+	var response models.AvailabilityResponse
+	checks := []struct {
+		column string
+		value  string
+		result **bool
+	}{
+		{"username", username, &response.UsernameAvailable},
+		{"email", email, &response.EmailAvailable},
+	}
+
+	for _, check := range checks {
+		if check.value == "" {
+			continue
+		}
+
+		var count int
+		err := h.DB.QueryRow(
+			"SELECT COUNT(*) FROM users WHERE "+check.column+" = ?", check.value,
+		).Scan(&count)
+		if err != nil {
+			h.Logger.Printf("Database error checking %s availability: %v", check.column, err)
+			models.WriteErrorResponse(w, http.StatusInternalServerError, "Internal server error")
+			return
+		}
+
+		available := count == 0
+		*check.result = &available
+	}
+
+	models.WriteJSONResponse(w, http.StatusOK, response)
+}
+
 // Profile handlers - implemented in Phase 1.2
 func (h *Handler) GetProfile(w http.ResponseWriter, r *http.Request) {
 	// Extract username from URL path
@@ -381,6 +786,22 @@ func (h *Handler) GetProfile(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// ?include= takes a comma-separated list; stats is the only option so far
+	includeStats := false
+	if include := r.URL.Query().Get("include"); include != "" {
+		for _, option := range strings.Split(include, ",") {
+			switch strings.TrimSpace(option) {
+			case "stats":
+				includeStats = true
+			default:
+				models.WriteErrorResponse(w, http.StatusUnprocessableEntity, models.ValidationErrors{
+					{Field: "include", Message: "must be stats"},
+				})
+				return
+			}
+		}
+	}
+
 	// Get user profile from database
 	var user models.User
 	err := h.DB.QueryRow(`
@@ -402,21 +823,144 @@ func (h *Handler) GetProfile(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Check if current user is following this profile (if authenticated)
+	// Check if current user is following or has blocked this profile (if authenticated)
 	following := false
+	blocked := false
 	if authUser, ok := middleware.GetUserFromContext(r.Context()); ok {
-		var followCount int
+		var followCount, blockCount int
 		h.DB.QueryRow(`
 			SELECT COUNT(*) FROM follows 
 			WHERE follower_id = ? AND following_id = ?
 		`, authUser.ID, user.ID).Scan(&followCount)
 		following = followCount > 0
+
+		h.DB.QueryRow(`
+			SELECT COUNT(*) FROM blocks
+			WHERE blocker_id = ? AND blocked_id = ?
+		`, authUser.ID, user.ID).Scan(&blockCount)
+		blocked = blockCount > 0
 	}
 
 	// Create profile response
 	response := models.ProfileResponse{
 		Profile: user.ToProfile(following),
 	}
+	response.Profile.Blocked = blocked
+
+	if includeStats {
+		stats, err := h.profileStats(user.ID)
+		if err != nil {
+			h.Logger.Printf("Database error getting profile stats: %v", err)
+			models.WriteErrorResponse(w, http.StatusInternalServerError, "Internal server error")
+			return
+		}
+		response.Stats = stats
+	}
+
+	models.WriteJSONResponse(w, http.StatusOK, response)
+}
+
+// profileStats counts a user's published articles, followers, and the
+// favorites those articles have received
+func (h *Handler) profileStats(userID int) (*models.ProfileStats, error) {
+	var stats models.ProfileStats
+	err := h.DB.QueryRow(`
+		SELECT
+			(SELECT COUNT(*) FROM articles WHERE author_id = ? AND published = 1),
+			(SELECT COUNT(*) FROM follows WHERE following_id = ?),
+			(SELECT COUNT(*) FROM favorites f JOIN articles a ON a.id = f.article_id
+			 WHERE a.author_id = ? AND a.published = 1)
+	`, userID, userID, userID).Scan(&stats.ArticlesCount, &stats.FollowersCount, &stats.FavoritesReceived)
+	if err != nil {
+		return nil, err
+	}
+	return &stats, nil
+}
+
+// maxBatchProfiles caps the usernames accepted by one batch profile lookup
+const maxBatchProfiles = 50
+
+// GetProfiles looks up several profiles at once from ?usernames=a,b,c,
+// returning them in the requested order and listing unknown names separately
+func (h *Handler) GetProfiles(w http.ResponseWriter, r *http.Request) {
+	// Get user ID for follow status (0 if not authenticated)
+	var userID int
+	if authUser, ok := middleware.GetUserFromContext(r.Context()); ok {
+		userID = authUser.ID
+	}
+
+	// Usernames are unique ignoring case, so duplicates are dropped the same way
+	var usernames []string
+	seen := make(map[string]bool)
+	for _, name := range strings.Split(r.URL.Query().Get("usernames"), ",") {
+		name = strings.TrimSpace(name)
+		if name == "" || seen[strings.ToLower(name)] {
+			continue
+		}
+		seen[strings.ToLower(name)] = true
+		usernames = append(usernames, name)
+	}
+
+	if len(usernames) == 0 {
+		models.WriteErrorResponse(w, http.StatusUnprocessableEntity, models.ValidationErrors{
+			{Field: "usernames", Message: "is required"},
+		})
+		return
+	}
+	if len(usernames) > maxBatchProfiles {
+		models.WriteErrorResponse(w, http.StatusUnprocessableEntity, models.ValidationErrors{
+			{Field: "usernames", Message: "must list at most " + strconv.Itoa(maxBatchProfiles) + " usernames"},
+		})
+		return
+	}
+
+	placeholders := strings.TrimSuffix(strings.Repeat("?,", len(usernames)), ",")
+	args := []interface{}{userID}
+	for _, name := range usernames {
+		args = append(args, name)
+	}
+
+	rows, err := h.DB.Query(`
+		SELECT u.username, u.bio, u.image,
+			EXISTS(SELECT 1 FROM follows f WHERE f.follower_id = ? AND f.following_id = u.id)
+		FROM users u
+		WHERE u.username IN (`+placeholders+`)
+	`, args...)
+	if err != nil {
+		h.Logger.Printf("Database error getting profiles: %v", err)
+		models.WriteErrorResponse(w, http.StatusInternalServerError, "Internal server error")
+		return
+	}
+	defer rows.Close()
+
+	found := make(map[string]models.Profile)
+	for rows.Next() {
+		var profile models.Profile
+		if err := rows.Scan(&profile.Username, &profile.Bio, &profile.Image, &profile.Following); err != nil {
+			h.Logger.Printf("Error scanning profile row: %v", err)
+			models.WriteErrorResponse(w, http.StatusInternalServerError, "Internal server error")
+			return
+		}
+		profile.Image = models.DisplayImage(profile.Image)
+		found[strings.ToLower(profile.Username)] = profile
+	}
+	if err := rows.Err(); err != nil {
+		h.Logger.Printf("Database error getting profiles: %v", err)
+		models.WriteErrorResponse(w, http.StatusInternalServerError, "Internal server error")
+		return
+	}
+
+	response := models.ProfilesResponse{
+		Profiles: make([]models.Profile, 0, len(found)),
+		NotFound: make([]string, 0),
+	}
+	for _, name := range usernames {
+		if profile, ok := found[strings.ToLower(name)]; ok {
+			response.Profiles = append(response.Profiles, profile)
+		} else {
+			response.NotFound = append(response.NotFound, name)
+		}
+	}
 
 	models.WriteJSONResponse(w, http.StatusOK, response)
 }
@@ -457,9 +1001,33 @@ func (h *Handler) FollowUser(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Prevent self-following
+	// Prevent self-following; lenient mode treats it as a no-op so clients
+	// can follow blindly, but still never stores the row
 	if authUser.ID == targetUser.ID {
-		models.WriteErrorResponse(w, http.StatusBadRequest, "Cannot follow yourself")
+		if !h.LenientSelfFollow {
+			models.WriteErrorResponse(w, http.StatusBadRequest, "Cannot follow yourself")
+			return
+		}
+		response := models.ProfileResponse{
+			Profile: targetUser.ToProfile(false),
+		}
+		if wantsChangeReport(r) {
+			changed := false
+			response.Changed = &changed
+		}
+		models.WriteJSONResponse(w, http.StatusOK, response)
+		return
+	}
+
+	// Blocks in either direction rule out a follow
+	blocked, err := h.isBlockedBetween(authUser.ID, targetUser.ID)
+	if err != nil {
+		h.Logger.Printf("Database error checking blocks: %v", err)
+		models.WriteErrorResponse(w, http.StatusInternalServerError, "Internal server error")
+		return
+	}
+	if blocked {
+		models.WriteErrorResponse(w, http.StatusForbidden, "Cannot follow this user")
 		return
 	}
 
@@ -471,8 +1039,37 @@ func (h *Handler) FollowUser(w http.ResponseWriter, r *http.Request) {
 	`, authUser.ID, targetUser.ID).Scan(&followCount)
 
 	if followCount == 0 {
+		// Only new follows use up the daily budget
+		if h.MaxFollowsPerDay > 0 {
+			var followsToday int
+			err := h.DB.QueryRow(`
+				SELECT COALESCE(SUM(count), 0) FROM daily_follow_counts
+				WHERE user_id = ? AND day = date('now')
+			`, authUser.ID).Scan(&followsToday)
+			if err != nil {
+				h.Logger.Printf("Database error counting follows: %v", err)
+				models.WriteErrorResponse(w, http.StatusInternalServerError, "Internal server error")
+				return
+			}
+			if followsToday >= h.MaxFollowsPerDay {
+				now := time.Now().UTC()
+				midnight := time.Date(now.Year(), now.Month(), now.Day()+1, 0, 0, 0, 0, time.UTC)
+				w.Header().Set("Retry-After", strconv.Itoa(int(midnight.Sub(now).Seconds())+1))
+				models.WriteErrorResponse(w, http.StatusTooManyRequests, fmt.Sprintf("You can follow at most %d users per day", h.MaxFollowsPerDay))
+				return
+			}
+		}
+
+		tx, err := h.DB.Begin()
+		if err != nil {
+			h.Logger.Printf("Database error starting transaction: %v", err)
+			models.WriteErrorResponse(w, http.StatusInternalServerError, "Internal server error")
+			return
+		}
+		defer tx.Rollback()
+
 		// Create follow relationship
-		_, err = h.DB.Exec(`
+		_, err = tx.Exec(`
 			INSERT INTO follows (follower_id, following_id) 
 			VALUES (?, ?)
 		`, authUser.ID, targetUser.ID)
@@ -482,15 +1079,40 @@ func (h *Handler) FollowUser(w http.ResponseWriter, r *http.Request) {
 			models.WriteErrorResponse(w, http.StatusInternalServerError, "Internal server error")
 			return
 		}
-	}
-
-	// Create profile response (always following = true after successful follow)
-	response := models.ProfileResponse{
-		Profile: targetUser.ToProfile(true),
-	}
-
-	models.WriteJSONResponse(w, http.StatusOK, response)
-}
+
+		// Count it toward today's budget and drop the user's older days
+		if _, err := tx.Exec(`
+			INSERT INTO daily_follow_counts (user_id, day, count) VALUES (?, date('now'), 1)
+			ON CONFLICT (user_id, day) DO UPDATE SET count = count + 1
+		`, authUser.ID); err != nil {
+			h.Logger.Printf("Database error counting follow: %v", err)
+			models.WriteErrorResponse(w, http.StatusInternalServerError, "Internal server error")
+			return
+		}
+		if _, err := tx.Exec("DELETE FROM daily_follow_counts WHERE user_id = ? AND day < date('now')", authUser.ID); err != nil {
+			h.Logger.Printf("Database error pruning follow counts: %v", err)
+			models.WriteErrorResponse(w, http.StatusInternalServerError, "Internal server error")
+			return
+		}
+
+		if err := tx.Commit(); err != nil {
+			h.Logger.Printf("Error committing transaction: %v", err)
+			models.WriteErrorResponse(w, http.StatusInternalServerError, "Internal server error")
+			return
+		}
+	}
+
+	// Create profile response (always following = true after successful follow)
+	response := models.ProfileResponse{
+		Profile: targetUser.ToProfile(true),
+	}
+	if wantsChangeReport(r) {
+		changed := followCount == 0
+		response.Changed = &changed
+	}
+
+	models.WriteJSONResponse(w, http.StatusOK, response)
+}
 
 func (h *Handler) UnfollowUser(w http.ResponseWriter, r *http.Request) {
 	// Get user from context (set by auth middleware)
@@ -529,7 +1151,7 @@ func (h *Handler) UnfollowUser(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Delete follow relationship (ignore if not following)
-	_, err = h.DB.Exec(`
+	result, err := h.DB.Exec(`
 		DELETE FROM follows 
 		WHERE follower_id = ? AND following_id = ?
 	`, authUser.ID, targetUser.ID)
@@ -544,10 +1166,53 @@ func (h *Handler) UnfollowUser(w http.ResponseWriter, r *http.Request) {
 	response := models.ProfileResponse{
 		Profile: targetUser.ToProfile(false),
 	}
+	if wantsChangeReport(r) {
+		removed, _ := result.RowsAffected()
+		changed := removed > 0
+		response.Changed = &changed
+	}
 
 	models.WriteJSONResponse(w, http.StatusOK, response)
 }
 
+// maxArticleAuthors caps the authors one article listing can filter by
+const maxArticleAuthors = 20
+
+// articleFiltersFromQuery reads the tag, author and favorited filters shared
+// by the article listing and count endpoints
+func articleFiltersFromQuery(query url.Values) (models.ArticleFilters, models.ValidationErrors) {
+	filters := models.ArticleFilters{
+		Tag:       query.Get("tag"),
+		Favorited: query.Get("favorited"),
+	}
+
+	// author may be repeated or comma-separated; duplicates are dropped
+	// ignoring case, as usernames are unique that way
+	var authors []string
+	seen := make(map[string]bool)
+	for _, value := range query["author"] {
+		for _, name := range strings.Split(value, ",") {
+			name = strings.TrimSpace(name)
+			if name == "" || seen[strings.ToLower(name)] {
+				continue
+			}
+			seen[strings.ToLower(name)] = true
+			authors = append(authors, name)
+		}
+	}
+	if len(authors) > maxArticleAuthors {
+		return filters, models.ValidationErrors{
+			{Field: "author", Message: "must list at most " + strconv.Itoa(maxArticleAuthors) + " authors"},
+		}
+	}
+	if len(authors) == 1 {
+		filters.Author = authors[0]
+	} else {
+		filters.Authors = authors
+	}
+	return filters, nil
+}
+
 // Article handlers - implemented in Phase 1.3
 func (h *Handler) ListArticles(w http.ResponseWriter, r *http.Request) {
 	// Get user ID for favorite/follow status (0 if not authenticated)
@@ -558,195 +1223,237 @@ func (h *Handler) ListArticles(w http.ResponseWriter, r *http.Request) {
 
 	// Parse query parameters
 	query := r.URL.Query()
-	filters := models.ArticleFilters{
-		Tag:       query.Get("tag"),
-		Author:    query.Get("author"),
-		Favorited: query.Get("favorited"),
-		Limit:     20, // default
-		Offset:    0,  // default
+	filters, errs := articleFiltersFromQuery(query)
+	if errs == nil {
+		filters.Limit, filters.Offset, errs = parsePagination(query)
+	}
+	if errs == nil {
+		filters.Sort = h.DefaultArticleSort
+		if sort := query.Get("sort"); sort != "" {
+			if !models.ValidArticleSort(sort) {
+				errs = models.ValidationErrors{{Field: "sort", Message: "must be one of " + strings.Join(models.ArticleSorts, ", ")}}
+			}
+			filters.Sort = sort
+		}
+	}
+	if errs != nil {
+		models.WriteErrorResponse(w, http.StatusUnprocessableEntity, errs)
+		return
+	}
+	if h.offsetTooDeep(w, filters.Offset) {
+		return
 	}
 
-	// Parse limit and offset
-	if limitStr := query.Get("limit"); limitStr != "" {
-		if limit := parseIntDefault(limitStr, 20); limit > 0 && limit <= 100 {
-			filters.Limit = limit
+	// Anonymous lists are the same for everyone, so they can be reused
+	// until ArticleListCacheTTL passes or an article changes
+	cacheable := h.ArticleListCacheTTL > 0 && userID == 0
+	cacheKey := query.Encode()
+	if cacheable {
+		if entry, ok := h.cachedArticleList(cacheKey); ok {
+			h.setArticleListCacheHeaders(w, true)
+			writeArticlesResponse(w, entry.articles, entry.totalCount, entry.suggestions, query.Get("fields"))
+			return
 		}
 	}
 
-	if offsetStr := query.Get("offset"); offsetStr != "" {
-		if offset := parseIntDefault(offsetStr, 0); offset >= 0 {
-			filters.Offset = offset
+	articles, totalCount, err := h.queryArticles(filters, userID)
+	if err != nil {
+		h.Logger.Printf("Database error listing articles: %v", err)
+		models.WriteErrorResponse(w, http.StatusInternalServerError, "Internal server error")
+		return
+	}
+
+	// Offer popular articles in place of an empty result with ?suggestOnEmpty=true
+	var suggestions []models.Article
+	if totalCount == 0 && h.EmptyListSuggestions > 0 && query.Get("suggestOnEmpty") == "true" {
+		suggestions, _, err = h.queryArticles(models.ArticleFilters{Popular: true, Limit: h.EmptyListSuggestions}, userID)
+		if err != nil {
+			h.Logger.Printf("Database error listing suggested articles: %v", err)
+			models.WriteErrorResponse(w, http.StatusInternalServerError, "Internal server error")
+			return
 		}
 	}
 
-	// Build the base query
-	baseQuery := `
-		SELECT DISTINCT
-			a.id, a.slug, a.title, a.description, a.body, a.author_id,
-			a.created_at, a.updated_at,
-			u.username, u.bio, u.image,
-			COALESCE(
-				(SELECT COUNT(*) FROM favorites f WHERE f.article_id = a.id AND f.user_id = ?), 
-				0
-			) > 0 as favorited,
-			(SELECT COUNT(*) FROM favorites f WHERE f.article_id = a.id) as favorites_count
-		FROM articles a
-		JOIN users u ON a.author_id = u.id
-	`
+	if cacheable {
+		h.cacheArticleList(cacheKey, articleListEntry{articles: articles, totalCount: totalCount, suggestions: suggestions})
+	}
+	if h.ArticleListCacheTTL > 0 {
+		h.setArticleListCacheHeaders(w, userID == 0)
+	}
 
-	countQuery := `
-		SELECT COUNT(DISTINCT a.id)
-		FROM articles a
-		JOIN users u ON a.author_id = u.id
-	`
+	writeArticlesResponse(w, articles, totalCount, suggestions, query.Get("fields"))
+}
 
-	// Build WHERE conditions
-	var conditions []string
-	var args []interface{}
-	var countArgs []interface{}
+// CountArticles returns how many articles match the ListArticles filters
+// without assembling the articles themselves
+func (h *Handler) CountArticles(w http.ResponseWriter, r *http.Request) {
+	var userID int
+	if authUser, ok := middleware.GetUserFromContext(r.Context()); ok {
+		userID = authUser.ID
+	}
 
-	args = append(args, userID)
-	
-	// Filter by tag
-	if filters.Tag != "" {
-		baseQuery += " JOIN article_tags at ON a.id = at.article_id JOIN tags t ON at.tag_id = t.id"
-		countQuery += " JOIN article_tags at ON a.id = at.article_id JOIN tags t ON at.tag_id = t.id"
-		conditions = append(conditions, "t.name = ?")
-		args = append(args, filters.Tag)
-		countArgs = append(countArgs, filters.Tag)
+	filters, errs := articleFiltersFromQuery(r.URL.Query())
+	if errs != nil {
+		models.WriteErrorResponse(w, http.StatusUnprocessableEntity, errs)
+		return
 	}
 
-	// Filter by author
-	if filters.Author != "" {
-		conditions = append(conditions, "u.username = ?")
-		args = append(args, filters.Author)
-		countArgs = append(countArgs, filters.Author)
+	count, err := h.countArticles(filters, userID)
+	if err != nil {
+		h.Logger.Printf("Database error counting articles: %v", err)
+		models.WriteErrorResponse(w, http.StatusInternalServerError, "Internal server error")
+		return
 	}
 
-	// Filter by favorited user
-	if filters.Favorited != "" {
-		baseQuery += " JOIN favorites fav ON a.id = fav.article_id JOIN users fav_user ON fav.user_id = fav_user.id"
-		countQuery += " JOIN favorites fav ON a.id = fav.article_id JOIN users fav_user ON fav.user_id = fav_user.id"
-		conditions = append(conditions, "fav_user.username = ?")
-		args = append(args, filters.Favorited)
-		countArgs = append(countArgs, filters.Favorited)
+	models.WriteJSONResponse(w, http.StatusOK, models.ArticlesCountResponse{ArticlesCount: count})
+}
+
+// GetTagArticles lists the articles carrying a tag, returning 404 for unknown tags
+func (h *Handler) GetTagArticles(w http.ResponseWriter, r *http.Request) {
+	// Extract tag name from URL path
+	name := r.PathValue("name")
+	if name == "" {
+		models.WriteErrorResponse(w, http.StatusBadRequest, "Tag name is required")
+		return
 	}
 
-	// Add WHERE clause if conditions exist
-	if len(conditions) > 0 {
-		whereClause := " WHERE " + strings.Join(conditions, " AND ")
-		baseQuery += whereClause
-		countQuery += whereClause
+	// Distinguish an unknown tag from a real tag with no articles
+	var tagCount int
+	err := h.DB.QueryRow("SELECT COUNT(*) FROM tags WHERE name = ?", name).Scan(&tagCount)
+	if err != nil {
+		h.Logger.Printf("Database error checking tag: %v", err)
+		models.WriteErrorResponse(w, http.StatusInternalServerError, "Internal server error")
+		return
 	}
 
-	// Add ordering and pagination
-	baseQuery += " ORDER BY a.created_at DESC LIMIT ? OFFSET ?"
-	args = append(args, filters.Limit, filters.Offset)
+	if tagCount == 0 {
+		models.WriteErrorResponse(w, http.StatusNotFound, "Tag not found")
+		return
+	}
 
-	// Get total count
-	var totalCount int
-	err := h.DB.QueryRow(countQuery, countArgs...).Scan(&totalCount)
+	// Get user ID for favorite/follow status (0 if not authenticated)
+	var userID int
+	if authUser, ok := middleware.GetUserFromContext(r.Context()); ok {
+		userID = authUser.ID
+	}
+
+	filters := models.ArticleFilters{Tag: name}
+	var errs models.ValidationErrors
+	filters.Limit, filters.Offset, errs = parsePagination(r.URL.Query())
+	if errs != nil {
+		models.WriteErrorResponse(w, http.StatusUnprocessableEntity, errs)
+		return
+	}
+	if h.offsetTooDeep(w, filters.Offset) {
+		return
+	}
+
+	articles, totalCount, err := h.queryArticles(filters, userID)
 	if err != nil {
-		h.Logger.Printf("Database error getting article count: %v", err)
+		h.Logger.Printf("Database error listing tag articles: %v", err)
 		models.WriteErrorResponse(w, http.StatusInternalServerError, "Internal server error")
 		return
 	}
 
-	// Get articles
-	rows, err := h.DB.Query(baseQuery, args...)
+	writeArticlesResponse(w, articles, totalCount, nil, "")
+}
+
+// GetProfileArticles lists a user's articles, returning 404 for unknown usernames
+func (h *Handler) GetProfileArticles(w http.ResponseWriter, r *http.Request) {
+	// Extract username from URL path
+	username := r.PathValue("username")
+	if username == "" {
+		models.WriteErrorResponse(w, http.StatusBadRequest, "Username is required")
+		return
+	}
+
+	// Resolve the author so an unknown username isn't reported as an empty list
+	var authorUsername string
+	err := h.DB.QueryRow("SELECT username FROM users WHERE username = ?", username).Scan(&authorUsername)
+	if err == sql.ErrNoRows {
+		models.WriteErrorResponse(w, http.StatusNotFound, "User not found")
+		return
+	}
+
 	if err != nil {
-		h.Logger.Printf("Database error getting articles: %v", err)
+		h.Logger.Printf("Database error getting profile: %v", err)
 		models.WriteErrorResponse(w, http.StatusInternalServerError, "Internal server error")
 		return
 	}
-	defer rows.Close()
 
-	var articles []models.Article
-	for rows.Next() {
-		var article models.Article
-		var authorUsername, authorBio, authorImage string
-		var favorited bool
-		var favoritesCount int
-
-		err := rows.Scan(
-			&article.ID, &article.Slug, &article.Title, &article.Description, 
-			&article.Body, &article.AuthorID, &article.CreatedAt, &article.UpdatedAt,
-			&authorUsername, &authorBio, &authorImage,
-			&favorited, &favoritesCount,
-		)
-		if err != nil {
-			h.Logger.Printf("Error scanning article row: %v", err)
-			models.WriteErrorResponse(w, http.StatusInternalServerError, "Internal server error")
-			return
-		}
+	// Get user ID for favorite/follow status (0 if not authenticated)
+	var userID int
+	if authUser, ok := middleware.GetUserFromContext(r.Context()); ok {
+		userID = authUser.ID
+	}
 
-		// Check if current user follows the author
-		var following bool
-		if userID > 0 {
-			var followCount int
-			h.DB.QueryRow(`
-				SELECT COUNT(*) FROM follows 
-				WHERE follower_id = ? AND following_id = ?
-			`, userID, article.AuthorID).Scan(&followCount)
-			following = followCount > 0
-		}
+	filters := models.ArticleFilters{Author: authorUsername, PinnedFirst: true}
+	var errs models.ValidationErrors
+	filters.Limit, filters.Offset, errs = parsePagination(r.URL.Query())
+	if errs != nil {
+		models.WriteErrorResponse(w, http.StatusUnprocessableEntity, errs)
+		return
+	}
+	if h.offsetTooDeep(w, filters.Offset) {
+		return
+	}
 
-		// Set article fields
-		article.Favorited = favorited
-		article.FavoritesCount = favoritesCount
-		article.Author = models.Profile{
-			Username:  authorUsername,
-			Bio:       authorBio,
-			Image:     authorImage,
-			Following: following,
-		}
+	articles, totalCount, err := h.queryArticles(filters, userID)
+	if err != nil {
+		h.Logger.Printf("Database error listing profile articles: %v", err)
+		models.WriteErrorResponse(w, http.StatusInternalServerError, "Internal server error")
+		return
+	}
 
-		// Get article tags
-		tagRows, err := h.DB.Query(`
-			SELECT t.name 
-			FROM tags t 
-			JOIN article_tags at ON t.id = at.tag_id 
-			WHERE at.article_id = ?
-			ORDER BY t.name
-		`, article.ID)
-		
-		if err != nil {
-			h.Logger.Printf("Error getting article tags: %v", err)
-			models.WriteErrorResponse(w, http.StatusInternalServerError, "Internal server error")
-			return
-		}
+	writeArticlesResponse(w, articles, totalCount, nil, "")
+}
 
-		var tags []string
-		for tagRows.Next() {
-			var tagName string
-			if err := tagRows.Scan(&tagName); err != nil {
-				tagRows.Close()
-				h.Logger.Printf("Error scanning tag: %v", err)
-				models.WriteErrorResponse(w, http.StatusInternalServerError, "Internal server error")
-				return
-			}
-			tags = append(tags, tagName)
-		}
-		tagRows.Close()
-		
-		article.TagList = tags
-		if article.TagList == nil {
-			article.TagList = make([]string, 0)
-		}
+// GetUserFavorites lists the current user's favorited articles, most
+// recently favorited first
+func (h *Handler) GetUserFavorites(w http.ResponseWriter, r *http.Request) {
+	authUser, ok := middleware.GetUserFromContext(r.Context())
+	if !ok {
+		models.WriteErrorResponse(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
 
-		articles = append(articles, article)
+	filters := models.ArticleFilters{FavoritesOf: authUser.ID}
+	var errs models.ValidationErrors
+	filters.Limit, filters.Offset, errs = parsePagination(r.URL.Query())
+	if errs != nil {
+		models.WriteErrorResponse(w, http.StatusUnprocessableEntity, errs)
+		return
+	}
+	if h.offsetTooDeep(w, filters.Offset) {
+		return
 	}
 
-	if articles == nil {
-		articles = make([]models.Article, 0)
+	articles, totalCount, err := h.queryArticles(filters, authUser.ID)
+	if err != nil {
+		h.Logger.Printf("Database error listing favorites: %v", err)
+		models.WriteErrorResponse(w, http.StatusInternalServerError, "Internal server error")
+		return
 	}
 
-	response := models.ArticlesResponse{
-		Articles:      articles,
-		ArticlesCount: totalCount,
+	// Every article here is a favorite, whatever the per-row lookup found
+	for i := range articles {
+		articles[i].Favorited = true
 	}
 
-	models.WriteJSONResponse(w, http.StatusOK, response)
+	writeArticlesResponse(w, articles, totalCount, nil, r.URL.Query().Get("fields"))
+}
+
+// feedClause returns the WHERE clause and arguments selecting a user's feed:
+// published articles from followed users, plus the user's own when
+// includeSelf is set
+func feedClause(userID int, includeSelf bool) (string, []interface{}) {
+	filter := "a.author_id IN (SELECT following_id FROM follows WHERE follower_id = ?)"
+	args := []interface{}{userID}
+	if includeSelf {
+		filter = "(" + filter + " OR a.author_id = ?)"
+		args = append(args, userID)
+	}
+	return "a.published = 1 AND " + filter, args
 }
 
 func (h *Handler) GetFeed(w http.ResponseWriter, r *http.Request) {
@@ -758,27 +1465,24 @@ func (h *Handler) GetFeed(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Parse query parameters for pagination
-	query := r.URL.Query()
-	limit := 20 // default
-	offset := 0 // default
-
-	if limitStr := query.Get("limit"); limitStr != "" {
-		if l := parseIntDefault(limitStr, 20); l > 0 && l <= 100 {
-			limit = l
-		}
+	limit, offset, errs := parsePagination(r.URL.Query())
+	if errs != nil {
+		models.WriteErrorResponse(w, http.StatusUnprocessableEntity, errs)
+		return
 	}
-
-	if offsetStr := query.Get("offset"); offsetStr != "" {
-		if o := parseIntDefault(offsetStr, 0); o >= 0 {
-			offset = o
-		}
+	if h.offsetTooDeep(w, offset) {
+		return
 	}
 
-	// Query articles from followed users
+	feedFilter, filterArgs := feedClause(authUser.ID, r.URL.Query().Get("includeSelf") == "true")
+
+	// The filter only uses IN subqueries and the users join is one-to-one, so
+	// each article matches at most once however many follow rows point at
+	// its author (including a legacy self-follow) and no DISTINCT is needed
 	baseQuery := `
-		SELECT DISTINCT
+		SELECT
 			a.id, a.slug, a.title, a.description, a.body, a.author_id,
-			a.created_at, a.updated_at,
+			a.created_at, a.updated_at, a.cover_image, a.version, a.comments_enabled, a.published, a.pinned,
 			u.username, u.bio, u.image,
 			COALESCE(
 				(SELECT COUNT(*) FROM favorites f WHERE f.article_id = a.id AND f.user_id = ?), 
@@ -787,22 +1491,19 @@ func (h *Handler) GetFeed(w http.ResponseWriter, r *http.Request) {
 			(SELECT COUNT(*) FROM favorites f WHERE f.article_id = a.id) as favorites_count
 		FROM articles a
 		JOIN users u ON a.author_id = u.id
-		JOIN follows f ON a.author_id = f.following_id
-		WHERE f.follower_id = ?
-		ORDER BY a.created_at DESC
+		WHERE ` + feedFilter + `
+		ORDER BY a.created_at DESC, a.id DESC
 		LIMIT ? OFFSET ?
 	`
 
 	countQuery := `
-		SELECT COUNT(DISTINCT a.id)
+		SELECT COUNT(*)
 		FROM articles a
-		JOIN follows f ON a.author_id = f.following_id
-		WHERE f.follower_id = ?
-	`
+		WHERE ` + feedFilter
 
 	// Get total count
 	var totalCount int
-	err := h.DB.QueryRow(countQuery, authUser.ID).Scan(&totalCount)
+	err := h.queryRow("GetFeed count", countQuery, filterArgs...).Scan(&totalCount)
 	if err != nil {
 		h.Logger.Printf("Database error getting feed count: %v", err)
 		models.WriteErrorResponse(w, http.StatusInternalServerError, "Internal server error")
@@ -810,7 +1511,9 @@ func (h *Handler) GetFeed(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Get articles
-	rows, err := h.DB.Query(baseQuery, authUser.ID, authUser.ID, limit, offset)
+	args := append([]interface{}{authUser.ID}, filterArgs...)
+	args = append(args, limit, offset)
+	rows, err := h.query("GetFeed", baseQuery, args...)
 	if err != nil {
 		h.Logger.Printf("Database error getting feed: %v", err)
 		models.WriteErrorResponse(w, http.StatusInternalServerError, "Internal server error")
@@ -827,7 +1530,7 @@ func (h *Handler) GetFeed(w http.ResponseWriter, r *http.Request) {
 
 		err := rows.Scan(
 			&article.ID, &article.Slug, &article.Title, &article.Description, 
-			&article.Body, &article.AuthorID, &article.CreatedAt, &article.UpdatedAt,
+			&article.Body, &article.AuthorID, &article.CreatedAt, &article.UpdatedAt, &article.CoverImage, &article.Version, &article.CommentsEnabled, &article.Published, &article.Pinned,
 			&authorUsername, &authorBio, &authorImage,
 			&favorited, &favoritesCount,
 		)
@@ -837,14 +1540,14 @@ func (h *Handler) GetFeed(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 
-		// User is always following authors in their feed
+		// User follows every author in their feed except themselves
 		article.Favorited = favorited
 		article.FavoritesCount = favoritesCount
 		article.Author = models.Profile{
 			Username:  authorUsername,
 			Bio:       authorBio,
-			Image:     authorImage,
-			Following: true, // Always true in feed
+			Image:     models.DisplayImage(authorImage),
+			Following: article.AuthorID != authUser.ID,
 		}
 
 		// Get article tags
@@ -853,7 +1556,7 @@ func (h *Handler) GetFeed(w http.ResponseWriter, r *http.Request) {
 			FROM tags t 
 			JOIN article_tags at ON t.id = at.tag_id 
 			WHERE at.article_id = ?
-			ORDER BY t.name
+			ORDER BY at.position, t.name
 		`, article.ID)
 		
 		if err != nil {
@@ -887,12 +1590,83 @@ func (h *Handler) GetFeed(w http.ResponseWriter, r *http.Request) {
 		articles = make([]models.Article, 0)
 	}
 
-	response := models.ArticlesResponse{
-		Articles:      articles,
-		ArticlesCount: totalCount,
+	// With ?discover=true, recommendations continue the feed once the
+	// followed articles run out, so a sparse feed still fills its pages
+	if r.URL.Query().Get("discover") == "true" {
+		for i := range articles {
+			articles[i].Source = models.ArticleSourceFollowing
+		}
+
+		recOffset := offset - totalCount
+		if recOffset < 0 {
+			recOffset = 0
+		}
+
+		recommended, recommendedCount, err := h.queryRecommendedArticles(authUser.ID, limit-len(articles), recOffset)
+		if err != nil {
+			h.Logger.Printf("Database error getting feed recommendations: %v", err)
+			models.WriteErrorResponse(w, http.StatusInternalServerError, "Internal server error")
+			return
+		}
+
+		articles = append(articles, recommended...)
+		totalCount += recommendedCount
 	}
 
-	models.WriteJSONResponse(w, http.StatusOK, response)
+	// Defensive: should a future filter change let an article match twice,
+	// clients still see it only once
+	articles = uniqueArticles(articles)
+
+	writeArticlesResponse(w, articles, totalCount, nil, r.URL.Query().Get("fields"))
+}
+
+// GetUnreadFeedCount counts the feed articles published after ?since, so
+// clients can badge new posts without fetching the feed itself
+func (h *Handler) GetUnreadFeedCount(w http.ResponseWriter, r *http.Request) {
+	authUser, ok := middleware.GetUserFromContext(r.Context())
+	if !ok {
+		models.WriteErrorResponse(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	query := r.URL.Query()
+	since, err := time.Parse(time.RFC3339, query.Get("since"))
+	if err != nil {
+		models.WriteErrorResponse(w, http.StatusUnprocessableEntity, models.ValidationErrors{
+			{Field: "since", Message: "must be an RFC 3339 timestamp"},
+		})
+		return
+	}
+
+	filter, args := feedClause(authUser.ID, query.Get("includeSelf") == "true")
+	args = append(args, since.UTC().Format(sqliteTimeFormat))
+
+	var count int
+	err = h.queryRow("GetUnreadFeedCount", `
+		SELECT COUNT(*)
+		FROM articles a
+		WHERE `+filter+` AND a.created_at > ?`, args...).Scan(&count)
+	if err != nil {
+		h.Logger.Printf("Database error counting unread feed articles: %v", err)
+		models.WriteErrorResponse(w, http.StatusInternalServerError, "Internal server error")
+		return
+	}
+
+	models.WriteJSONResponse(w, http.StatusOK, models.UnreadCountResponse{Count: count})
+}
+
+// uniqueArticles drops repeated articles by ID, keeping the first occurrence
+func uniqueArticles(articles []models.Article) []models.Article {
+	seen := make(map[int]bool, len(articles))
+	unique := articles[:0]
+	for _, article := range articles {
+		if seen[article.ID] {
+			continue
+		}
+		seen[article.ID] = true
+		unique = append(unique, article)
+	}
+	return unique
 }
 
 func (h *Handler) GetArticle(w http.ResponseWriter, r *http.Request) {
@@ -929,6 +1703,68 @@ func (h *Handler) GetArticle(w http.ResponseWriter, r *http.Request) {
 	models.WriteJSONResponse(w, http.StatusOK, response)
 }
 
+// maxSlugAttempts bounds how often CreateArticle and CloneArticle regenerate
+// a slug that lost a race to a concurrent create
+const maxSlugAttempts = 5
+
+// slugChecker looks up whether slugs are taken within a transaction. The
+// GenerateUniqueSlug callback can't return an error, so the first lookup
+// failure is kept in err for the caller to check afterwards.
+type slugChecker struct {
+	tx  *sql.Tx
+	err error
+}
+
+// exists reports whether slug is taken. After a failed lookup it reports
+// false so generation stops; the caller must then check err.
+func (c *slugChecker) exists(slug string) bool {
+	if c.err != nil {
+		return false
+	}
+	var count int
+	if err := c.tx.QueryRow("SELECT COUNT(*) FROM articles WHERE slug = ?", slug).Scan(&count); err != nil {
+		c.err = err
+		return false
+	}
+	return count > 0
+}
+
+// isSlugConflict reports whether err is the articles.slug UNIQUE constraint
+// rejecting a write
+func isSlugConflict(err error) bool {
+	var sqliteErr sqlite3.Error
+	return errors.As(err, &sqliteErr) &&
+		sqliteErr.ExtendedCode == sqlite3.ErrConstraintUnique &&
+		strings.Contains(sqliteErr.Error(), "articles.slug")
+}
+
+// beginArticleCooldown records that the user is creating an article in tx,
+// or writes a 429 and returns false when their last one was less than
+// ArticleCooldown ago. Every way of creating articles calls it inside its
+// transaction, which holds the write lock, so concurrent creates can't both
+// pass; a rolled back create doesn't start the cooldown. Admins are exempt.
+func (h *Handler) beginArticleCooldown(w http.ResponseWriter, tx *sql.Tx, user *middleware.User) bool {
+	var lastArticle models.Timestamp
+	if err := tx.QueryRow("SELECT last_article_at FROM users WHERE id = ?", user.ID).Scan(&lastArticle); err != nil {
+		h.Logger.Printf("Database error checking article cooldown: %v", err)
+		models.WriteErrorResponse(w, http.StatusInternalServerError, "Internal server error")
+		return false
+	}
+
+	if wait := h.ArticleCooldown - time.Since(lastArticle.Time); h.ArticleCooldown > 0 && wait > 0 && !h.IsAdmin(user) {
+		w.Header().Set("Retry-After", strconv.Itoa(int(wait.Seconds())+1))
+		models.WriteErrorResponse(w, http.StatusTooManyRequests, "You are creating articles too quickly; please wait before posting again")
+		return false
+	}
+
+	if _, err := tx.Exec("UPDATE users SET last_article_at = CURRENT_TIMESTAMP WHERE id = ?", user.ID); err != nil {
+		h.Logger.Printf("Database error recording article cooldown: %v", err)
+		models.WriteErrorResponse(w, http.StatusInternalServerError, "Internal server error")
+		return false
+	}
+	return true
+}
+
 func (h *Handler) CreateArticle(w http.ResponseWriter, r *http.Request) {
 	// Get user from context (set by auth middleware)
 	authUser, ok := middleware.GetUserFromContext(r.Context())
@@ -949,14 +1785,6 @@ func (h *Handler) CreateArticle(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Generate unique slug
-	checkSlugExists := func(slug string) bool {
-		var count int
-		h.DB.QueryRow("SELECT COUNT(*) FROM articles WHERE slug = ?", slug).Scan(&count)
-		return count > 0
-	}
-	slug := utils.GenerateUniqueSlug(req.Article.Title, checkSlugExists)
-
 	// Begin transaction
 	tx, err := h.DB.Begin()
 	if err != nil {
@@ -966,13 +1794,54 @@ func (h *Handler) CreateArticle(w http.ResponseWriter, r *http.Request) {
 	}
 	defer tx.Rollback()
 
-	// Insert article
-	result, err := tx.Exec(`
-		INSERT INTO articles (slug, title, description, body, author_id) 
-		VALUES (?, ?, ?, ?, ?)
-	`, slug, req.Article.Title, req.Article.Description, req.Article.Body, authUser.ID)
-	
-	if err != nil {
+	if !h.beginArticleCooldown(w, tx, authUser) {
+		return
+	}
+
+	// Insert article (an empty cover image is stored as NULL)
+	var coverImage interface{}
+	if req.Article.CoverImage != "" {
+		coverImage = req.Article.CoverImage
+	}
+	commentsEnabled := req.Article.CommentsEnabled == nil || *req.Article.CommentsEnabled
+	published := req.Article.Published == nil || *req.Article.Published
+
+	// Use the custom slug when given, otherwise generate a unique one. The
+	// transaction holds the write lock from its start, so the slugs checked
+	// here can't be taken before the insert; the UNIQUE constraint remains
+	// the guarantee, and a generated slug that still collides is retried.
+	slugs := slugChecker{tx: tx}
+	var slug string
+	var result sql.Result
+	for attempt := 1; ; attempt++ {
+		slug = req.Article.Slug
+		if slug == "" {
+			slug = utils.GenerateUniqueSlug(req.Article.Title, h.SlugStrategy, slugs.exists)
+			if slugs.err != nil {
+				h.Logger.Printf("Database error checking slug: %v", slugs.err)
+				models.WriteErrorResponse(w, http.StatusInternalServerError, "Internal server error")
+				return
+			}
+		}
+
+		result, err = tx.Exec(`
+			INSERT INTO articles (slug, title, description, body, author_id, cover_image, comments_enabled, published) 
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+		`, slug, req.Article.Title, req.Article.Description, req.Article.Body, authUser.ID, coverImage, commentsEnabled, published)
+		if err == nil {
+			break
+		}
+
+		if isSlugConflict(err) {
+			if req.Article.Slug != "" {
+				models.WriteErrorResponse(w, http.StatusUnprocessableEntity, models.ValidationErrors{{Field: "slug", Message: "is already taken"}})
+				return
+			}
+			if attempt < maxSlugAttempts {
+				continue
+			}
+		}
+
 		h.Logger.Printf("Database error creating article: %v", err)
 		models.WriteErrorResponse(w, http.StatusInternalServerError, "Internal server error")
 		return
@@ -986,36 +1855,10 @@ func (h *Handler) CreateArticle(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Handle tags
-	for _, tagName := range req.Article.TagList {
-		if tagName == "" {
-			continue
-		}
-		
-		// Insert or get tag
-		var tagID int64
-		err = tx.QueryRow("SELECT id FROM tags WHERE name = ?", tagName).Scan(&tagID)
-		if err == sql.ErrNoRows {
-			// Create new tag
-			tagResult, err := tx.Exec("INSERT INTO tags (name) VALUES (?)", tagName)
-			if err != nil {
-				h.Logger.Printf("Error creating tag: %v", err)
-				models.WriteErrorResponse(w, http.StatusInternalServerError, "Internal server error")
-				return
-			}
-			tagID, _ = tagResult.LastInsertId()
-		} else if err != nil {
-			h.Logger.Printf("Error querying tag: %v", err)
-			models.WriteErrorResponse(w, http.StatusInternalServerError, "Internal server error")
-			return
-		}
-
-		// Link article to tag
-		_, err = tx.Exec("INSERT OR IGNORE INTO article_tags (article_id, tag_id) VALUES (?, ?)", articleID, tagID)
-		if err != nil {
-			h.Logger.Printf("Error linking article to tag: %v", err)
-			models.WriteErrorResponse(w, http.StatusInternalServerError, "Internal server error")
-			return
-		}
+	if err := addArticleTags(tx, articleID, req.Article.TagList); err != nil {
+		h.Logger.Printf("Error saving article tags: %v", err)
+		models.WriteErrorResponse(w, http.StatusInternalServerError, "Internal server error")
+		return
 	}
 
 	// Commit transaction
@@ -1024,6 +1867,8 @@ func (h *Handler) CreateArticle(w http.ResponseWriter, r *http.Request) {
 		models.WriteErrorResponse(w, http.StatusInternalServerError, "Internal server error")
 		return
 	}
+	h.invalidateTags()
+	h.invalidateArticleLists()
 
 	// Get the created article with all details
 	article, err := h.getArticleBySlug(slug, authUser.ID)
@@ -1041,21 +1886,27 @@ func (h *Handler) CreateArticle(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *Handler) UpdateArticle(w http.ResponseWriter, r *http.Request) {
-	// Get user from context (set by auth middleware)
-	authUser, ok := middleware.GetUserFromContext(r.Context())
-	if !ok {
-		models.WriteErrorResponse(w, http.StatusUnauthorized, "Unauthorized")
+	var req models.UpdateArticleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		models.WriteErrorResponse(w, http.StatusBadRequest, "Invalid JSON format")
 		return
 	}
 
-	// Extract slug from URL path
-	slug := r.PathValue("slug")
-	if slug == "" {
-		models.WriteErrorResponse(w, http.StatusBadRequest, "Article slug is required")
+	// Validate request
+	if validationErrors := req.Validate(); len(validationErrors) > 0 {
+		models.WriteErrorResponse(w, http.StatusUnprocessableEntity, validationErrors)
 		return
 	}
 
-	var req models.UpdateArticleRequest
+	// PUT keeps treating empty strings as "no change"
+	patch := req.ToPatch()
+	h.applyArticleUpdate(w, r, &patch)
+}
+
+// PatchArticle partially updates an article: omitted fields are unchanged and
+// explicit empty strings clear the field where that is allowed
+func (h *Handler) PatchArticle(w http.ResponseWriter, r *http.Request) {
+	var req models.PatchArticleRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		models.WriteErrorResponse(w, http.StatusBadRequest, "Invalid JSON format")
 		return
@@ -1067,6 +1918,25 @@ func (h *Handler) UpdateArticle(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	h.applyArticleUpdate(w, r, &req)
+}
+
+// applyArticleUpdate writes a validated partial update to the article named in the URL
+func (h *Handler) applyArticleUpdate(w http.ResponseWriter, r *http.Request, req *models.PatchArticleRequest) {
+	// Get user from context (set by auth middleware)
+	authUser, ok := middleware.GetUserFromContext(r.Context())
+	if !ok {
+		models.WriteErrorResponse(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	// Extract slug from URL path
+	slug := r.PathValue("slug")
+	if slug == "" {
+		models.WriteErrorResponse(w, http.StatusBadRequest, "Article slug is required")
+		return
+	}
+
 	// Get current article to verify ownership
 	var currentArticle models.Article
 	err := h.DB.QueryRow(`
@@ -1095,6 +1965,17 @@ func (h *Handler) UpdateArticle(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// The version the client edited comes from If-Match or the request body
+	expectedVersion := req.Article.Version
+	if ifMatch := r.Header.Get("If-Match"); ifMatch != "" && ifMatch != "*" {
+		version, err := parseVersionTag(ifMatch)
+		if err != nil {
+			models.WriteErrorResponse(w, http.StatusBadRequest, "Invalid If-Match header")
+			return
+		}
+		expectedVersion = &version
+	}
+
 	// Begin transaction
 	tx, err := h.DB.Begin()
 	if err != nil {
@@ -1108,51 +1989,95 @@ func (h *Handler) UpdateArticle(w http.ResponseWriter, r *http.Request) {
 	updateValues := make(map[string]interface{})
 	newSlug := slug
 
-	if req.Article.Title != "" && req.Article.Title != currentArticle.Title {
-		updateValues["title"] = req.Article.Title
-		
-		// Generate new slug if title changed
-		checkSlugExists := func(s string) bool {
-			if s == slug {
-				return false // Current slug is allowed
-			}
-			var count int
-			h.DB.QueryRow("SELECT COUNT(*) FROM articles WHERE slug = ?", s).Scan(&count)
-			return count > 0
+	// A custom slug replaces the current one and stops a title change from
+	// regenerating it
+	if req.Article.Slug != nil && *req.Article.Slug != slug {
+		var count int
+		if err := h.DB.QueryRow("SELECT COUNT(*) FROM articles WHERE slug = ?", *req.Article.Slug).Scan(&count); err != nil {
+			h.Logger.Printf("Database error checking slug: %v", err)
+			models.WriteErrorResponse(w, http.StatusInternalServerError, "Internal server error")
+			return
+		}
+		if count > 0 {
+			models.WriteErrorResponse(w, http.StatusUnprocessableEntity, models.ValidationErrors{{Field: "slug", Message: "is already taken"}})
+			return
 		}
-		newSlug = utils.GenerateUniqueSlug(req.Article.Title, checkSlugExists)
+		newSlug = *req.Article.Slug
 		updateValues["slug"] = newSlug
 	}
 
-	if req.Article.Description != "" {
-		updateValues["description"] = req.Article.Description
+	if req.Article.Title != nil && *req.Article.Title != currentArticle.Title {
+		updateValues["title"] = *req.Article.Title
+		
+		// Generate new slug if title changed (random slugs are independent of the title)
+		if h.SlugStrategy != utils.SlugStrategyRandom && req.Article.Slug == nil {
+			checkSlugExists := func(s string) bool {
+				if s == slug {
+					return false // Current slug is allowed
+				}
+				var count int
+				h.DB.QueryRow("SELECT COUNT(*) FROM articles WHERE slug = ?", s).Scan(&count)
+				return count > 0
+			}
+			newSlug = utils.GenerateUniqueSlug(*req.Article.Title, h.SlugStrategy, checkSlugExists)
+			updateValues["slug"] = newSlug
+		}
 	}
 
-	if req.Article.Body != "" {
-		updateValues["body"] = req.Article.Body
+	if req.Article.Description != nil {
+		updateValues["description"] = *req.Article.Description
 	}
 
-	// Update article if there are changes
-	if len(updateValues) > 0 {
-		query := "UPDATE articles SET "
-		args := make([]interface{}, 0, len(updateValues)+1)
-		setParts := make([]string, 0, len(updateValues))
+	if req.Article.Body != nil {
+		updateValues["body"] = *req.Article.Body
+	}
 
-		for field, value := range updateValues {
-			setParts = append(setParts, field+" = ?")
-			args = append(args, value)
+	if req.Article.CoverImage != nil {
+		// An explicit empty value clears the cover image
+		if *req.Article.CoverImage == "" {
+			updateValues["cover_image"] = nil
+		} else {
+			updateValues["cover_image"] = *req.Article.CoverImage
 		}
+	}
 
-		query += strings.Join(setParts, ", ")
-		query += " WHERE id = ?"
-		args = append(args, currentArticle.ID)
+	if req.Article.CommentsEnabled != nil {
+		updateValues["comments_enabled"] = *req.Article.CommentsEnabled
+	}
 
-		_, err = tx.Exec(query, args...)
-		if err != nil {
-			h.Logger.Printf("Database error updating article: %v", err)
-			models.WriteErrorResponse(w, http.StatusInternalServerError, "Internal server error")
-			return
-		}
+	if req.Article.Published != nil {
+		updateValues["published"] = *req.Article.Published
+	}
+
+	// Every update bumps the version. When the client named the version it
+	// edited, the update only applies if nobody has changed the article since.
+	query := "UPDATE articles SET "
+	args := make([]interface{}, 0, len(updateValues)+2)
+	setParts := []string{"version = version + 1"}
+
+	for field, value := range updateValues {
+		setParts = append(setParts, field+" = ?")
+		args = append(args, value)
+	}
+
+	query += strings.Join(setParts, ", ")
+	query += " WHERE id = ?"
+	args = append(args, currentArticle.ID)
+	if expectedVersion != nil {
+		query += " AND version = ?"
+		args = append(args, *expectedVersion)
+	}
+
+	result, err := tx.Exec(query, args...)
+	if err != nil {
+		h.Logger.Printf("Database error updating article: %v", err)
+		models.WriteErrorResponse(w, http.StatusInternalServerError, "Internal server error")
+		return
+	}
+
+	if updated, _ := result.RowsAffected(); updated == 0 {
+		models.WriteErrorResponse(w, http.StatusConflict, "Article was modified by someone else; reload it and try again")
+		return
 	}
 
 	// Handle tags if provided
@@ -1166,36 +2091,10 @@ func (h *Handler) UpdateArticle(w http.ResponseWriter, r *http.Request) {
 		}
 
 		// Add new tags
-		for _, tagName := range req.Article.TagList {
-			if tagName == "" {
-				continue
-			}
-			
-			// Insert or get tag
-			var tagID int64
-			err = tx.QueryRow("SELECT id FROM tags WHERE name = ?", tagName).Scan(&tagID)
-			if err == sql.ErrNoRows {
-				// Create new tag
-				tagResult, err := tx.Exec("INSERT INTO tags (name) VALUES (?)", tagName)
-				if err != nil {
-					h.Logger.Printf("Error creating tag: %v", err)
-					models.WriteErrorResponse(w, http.StatusInternalServerError, "Internal server error")
-					return
-				}
-				tagID, _ = tagResult.LastInsertId()
-			} else if err != nil {
-				h.Logger.Printf("Error querying tag: %v", err)
-				models.WriteErrorResponse(w, http.StatusInternalServerError, "Internal server error")
-				return
-			}
-
-			// Link article to tag
-			_, err = tx.Exec("INSERT OR IGNORE INTO article_tags (article_id, tag_id) VALUES (?, ?)", currentArticle.ID, tagID)
-			if err != nil {
-				h.Logger.Printf("Error linking article to tag: %v", err)
-				models.WriteErrorResponse(w, http.StatusInternalServerError, "Internal server error")
-				return
-			}
+		if err := addArticleTags(tx, int64(currentArticle.ID), req.Article.TagList); err != nil {
+			h.Logger.Printf("Error saving article tags: %v", err)
+			models.WriteErrorResponse(w, http.StatusInternalServerError, "Internal server error")
+			return
 		}
 	}
 
@@ -1205,6 +2104,8 @@ func (h *Handler) UpdateArticle(w http.ResponseWriter, r *http.Request) {
 		models.WriteErrorResponse(w, http.StatusInternalServerError, "Internal server error")
 		return
 	}
+	h.invalidateTags()
+	h.invalidateArticleLists()
 
 	// Get updated article
 	article, err := h.getArticleBySlug(newSlug, authUser.ID)
@@ -1266,6 +2167,8 @@ func (h *Handler) DeleteArticle(w http.ResponseWriter, r *http.Request) {
 		models.WriteErrorResponse(w, http.StatusInternalServerError, "Internal server error")
 		return
 	}
+	h.invalidateTags()
+	h.invalidateArticleLists()
 
 	// Return 200 OK with empty response
 	w.Header().Set("Content-Type", "application/json; charset=utf-8")
@@ -1288,9 +2191,9 @@ func (h *Handler) FavoriteArticle(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Check if article exists and get its ID
+	// Check if article exists and get its ID; drafts only exist for their author
 	var articleID int
-	err := h.DB.QueryRow("SELECT id FROM articles WHERE slug = ?", slug).Scan(&articleID)
+	err := h.DB.QueryRow("SELECT id FROM articles WHERE slug = ? AND (published = 1 OR author_id = ?)", slug, authUser.ID).Scan(&articleID)
 	if err == sql.ErrNoRows {
 		models.WriteErrorResponse(w, http.StatusNotFound, "Article not found")
 		return
@@ -1302,7 +2205,8 @@ func (h *Handler) FavoriteArticle(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Add to favorites (ignore if already favorited)
+	// Add to favorites (ignore if already favorited). The (user_id, article_id)
+	// primary key means a repeated favorite can never inflate favoritesCount.
 	_, err = h.DB.Exec(`
 		INSERT OR IGNORE INTO favorites (user_id, article_id) 
 		VALUES (?, ?)
@@ -1322,6 +2226,9 @@ func (h *Handler) FavoriteArticle(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// The favorite is in place whether or not this request inserted it
+	article.Favorited = true
+
 	response := models.ArticleResponse{
 		Article: *article,
 	}
@@ -1344,9 +2251,9 @@ func (h *Handler) UnfavoriteArticle(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Check if article exists and get its ID
+	// Check if article exists and get its ID; drafts only exist for their author
 	var articleID int
-	err := h.DB.QueryRow("SELECT id FROM articles WHERE slug = ?", slug).Scan(&articleID)
+	err := h.DB.QueryRow("SELECT id FROM articles WHERE slug = ? AND (published = 1 OR author_id = ?)", slug, authUser.ID).Scan(&articleID)
 	if err == sql.ErrNoRows {
 		models.WriteErrorResponse(w, http.StatusNotFound, "Article not found")
 		return
@@ -1378,6 +2285,9 @@ func (h *Handler) UnfavoriteArticle(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// The favorite is gone whether or not this request removed it
+	article.Favorited = false
+
 	response := models.ArticleResponse{
 		Article: *article,
 	}
@@ -1385,46 +2295,416 @@ func (h *Handler) UnfavoriteArticle(w http.ResponseWriter, r *http.Request) {
 	models.WriteJSONResponse(w, http.StatusOK, response)
 }
 
-// Comment handlers - to be implemented in Phase 1.4
-func (h *Handler) GetComments(w http.ResponseWriter, r *http.Request) {
-	models.WriteErrorResponse(w, http.StatusNotImplemented, "GetComments endpoint not implemented yet")
+// SetFavorite sets whether the current user has favorited an article from
+// {"favorited": true|false}, so replaying it is safe whatever the prior state
+func (h *Handler) SetFavorite(w http.ResponseWriter, r *http.Request) {
+	var req models.SetFavoriteRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		models.WriteErrorResponse(w, http.StatusBadRequest, "Invalid JSON format")
+		return
+	}
+
+	if req.Favorited == nil {
+		models.WriteErrorResponse(w, http.StatusUnprocessableEntity, models.ValidationErrors{
+			{Field: "favorited", Message: "is required"},
+		})
+		return
+	}
+
+	// Both already answer with the resulting state rather than an error
+	if *req.Favorited {
+		h.FavoriteArticle(w, r)
+	} else {
+		h.UnfavoriteArticle(w, r)
+	}
+}
+
+// Helper functions
+
+// addArticleTags links an article to its tags in the given order, creating
+// tags that don't exist yet. Empty names are skipped and repeats keep their
+// first position.
+func addArticleTags(tx *sql.Tx, articleID int64, tags []string) error {
+	for position, tagName := range tags {
+		if tagName == "" {
+			continue
+		}
+
+		// Insert or get tag
+		var tagID int64
+		err := tx.QueryRow("SELECT id FROM tags WHERE name = ?", tagName).Scan(&tagID)
+		if err == sql.ErrNoRows {
+			tagResult, err := tx.Exec("INSERT INTO tags (name) VALUES (?)", tagName)
+			if err != nil {
+				return fmt.Errorf("creating tag: %w", err)
+			}
+			tagID, _ = tagResult.LastInsertId()
+		} else if err != nil {
+			return fmt.Errorf("querying tag: %w", err)
+		}
+
+		// Link article to tag
+		if _, err := tx.Exec("INSERT OR IGNORE INTO article_tags (article_id, tag_id, position) VALUES (?, ?, ?)", articleID, tagID, position); err != nil {
+			return fmt.Errorf("linking article to tag: %w", err)
+		}
+	}
+	return nil
+}
+
+// parseVersionTag reads an article version from an If-Match value, accepting
+// a bare number or an entity tag such as "3" or W/"3"
+func parseVersionTag(tag string) (int, error) {
+	tag = strings.TrimPrefix(strings.TrimSpace(tag), "W/")
+	return strconv.Atoi(strings.Trim(tag, `"`))
+}
+
+// wantsChangeReport reports whether the client asked for the "changed" flag on
+// follow/unfollow responses, keeping the default response shape unchanged
+func wantsChangeReport(r *http.Request) bool {
+	return r.URL.Query().Get("reportChange") == "true"
 }
 
-func (h *Handler) CreateComment(w http.ResponseWriter, r *http.Request) {
-	models.WriteErrorResponse(w, http.StatusNotImplemented, "CreateComment endpoint not implemented yet")
+// Pagination bounds shared by every list endpoint
+const (
+	defaultPageLimit = 20
+	maxPageLimit     = 100
+)
+
+// parsePagination reads limit and offset query parameters. Missing values fall
+// back to defaults, a limit above the maximum is clamped to the maximum, and
+// non-numeric or negative values are reported as validation errors.
+func parsePagination(query url.Values) (limit, offset int, errs models.ValidationErrors) {
+	limit = defaultPageLimit
+	offset = 0
+
+	if limitStr := query.Get("limit"); limitStr != "" {
+		l, err := strconv.Atoi(limitStr)
+		switch {
+		case err != nil:
+			errs = append(errs, models.ValidationError{Field: "limit", Message: "must be a number"})
+		case l < 1:
+			errs = append(errs, models.ValidationError{Field: "limit", Message: "must be at least 1"})
+		case l > maxPageLimit:
+			limit = maxPageLimit
+		default:
+			limit = l
+		}
+	}
+
+	if offsetStr := query.Get("offset"); offsetStr != "" {
+		o, err := strconv.Atoi(offsetStr)
+		switch {
+		case err != nil:
+			errs = append(errs, models.ValidationError{Field: "offset", Message: "must be a number"})
+		case o < 0:
+			errs = append(errs, models.ValidationError{Field: "offset", Message: "must not be negative"})
+		default:
+			offset = o
+		}
+	}
+
+	return limit, offset, errs
 }
 
-func (h *Handler) DeleteComment(w http.ResponseWriter, r *http.Request) {
-	models.WriteErrorResponse(w, http.StatusNotImplemented, "DeleteComment endpoint not implemented yet")
+// offsetTooDeep writes a 400 and returns true when offset is past
+// MaxPageOffset
+func (h *Handler) offsetTooDeep(w http.ResponseWriter, offset int) bool {
+	if h.MaxPageOffset == 0 || offset <= h.MaxPageOffset {
+		return false
+	}
+	models.WriteErrorResponse(w, http.StatusBadRequest, fmt.Sprintf("offset must be at most %d; narrow the filters instead of paging this deep", h.MaxPageOffset))
+	return true
 }
 
-// Tag handlers - to be implemented in Phase 1.4
-func (h *Handler) GetTags(w http.ResponseWriter, r *http.Request) {
-	models.WriteErrorResponse(w, http.StatusNotImplemented, "GetTags endpoint not implemented yet")
+// writeArticlesResponse writes an article list with body excerpts, shaped by
+// the ?fields= value: "summary" drops the bodies, a comma-separated list of
+// article fields keeps only those, and anything else gives full articles
+func writeArticlesResponse(w http.ResponseWriter, articles []models.Article, totalCount int, suggestions []models.Article, fields string) {
+	for i := range articles {
+		articles[i].Excerpt = models.Excerpt(articles[i].Body)
+	}
+	for i := range suggestions {
+		suggestions[i].Excerpt = models.Excerpt(suggestions[i].Body)
+	}
+
+	// Unknown names are ignored; if none are left the response stays full
+	if selected := models.ParseArticleFields(fields); fields != "summary" && len(selected) > 0 {
+		partial := make([]map[string]interface{}, 0, len(articles))
+		for i := range articles {
+			partial = append(partial, articles[i].SelectFields(selected))
+		}
+		var partialSuggestions []map[string]interface{}
+		for i := range suggestions {
+			partialSuggestions = append(partialSuggestions, suggestions[i].SelectFields(selected))
+		}
+
+		models.WriteJSONResponse(w, http.StatusOK, models.PartialArticlesResponse{
+			Articles:      partial,
+			ArticlesCount: totalCount,
+			Suggestions:   partialSuggestions,
+		})
+		return
+	}
+
+	if fields != "summary" {
+		models.WriteJSONResponse(w, http.StatusOK, models.ArticlesResponse{
+			Articles:      articles,
+			ArticlesCount: totalCount,
+			Suggestions:   suggestions,
+		})
+		return
+	}
+
+	summaries := make([]models.ArticleSummary, 0, len(articles))
+	for i := range articles {
+		summaries = append(summaries, articles[i].ToSummary())
+	}
+	var suggestionSummaries []models.ArticleSummary
+	for i := range suggestions {
+		suggestionSummaries = append(suggestionSummaries, suggestions[i].ToSummary())
+	}
+
+	models.WriteJSONResponse(w, http.StatusOK, models.ArticleSummariesResponse{
+		Articles:      summaries,
+		ArticlesCount: totalCount,
+		Suggestions:   suggestionSummaries,
+	})
 }
 
-// Helper functions
+// articleFilterClause builds the joins and WHERE clause selecting the articles
+// that match filters for the viewer, to follow "FROM articles a JOIN users u"
+func articleFilterClause(filters models.ArticleFilters, userID int) (string, []interface{}) {
+	var joins string
+	var conditions []string
+	var args []interface{}
+
+	// Drafts are only listed for their author, and never as popular
+	if filters.Popular {
+		conditions = append(conditions, "a.published = 1")
+	} else {
+		conditions = append(conditions, "(a.published = 1 OR a.author_id = ?)")
+		args = append(args, userID)
+	}
+
+	// Hide authors the viewer has blocked
+	if userID > 0 {
+		conditions = append(conditions, excludeBlockedAuthors("a.author_id"))
+		args = append(args, userID)
+	}
+	
+	// Filter by tag
+	if filters.Tag != "" {
+		joins += " JOIN article_tags at ON a.id = at.article_id JOIN tags t ON at.tag_id = t.id"
+		conditions = append(conditions, "t.name = ?")
+		args = append(args, filters.Tag)
+	}
+
+	// Filter by author
+	if filters.Author != "" {
+		conditions = append(conditions, "u.username = ?")
+		args = append(args, filters.Author)
+	}
+	if len(filters.Authors) > 0 {
+		placeholders := strings.TrimSuffix(strings.Repeat("?,", len(filters.Authors)), ",")
+		conditions = append(conditions, "u.username IN ("+placeholders+")")
+		for _, name := range filters.Authors {
+			args = append(args, name)
+		}
+	}
+
+	// Filter by favorited user
+	if filters.Favorited != "" {
+		joins += " JOIN favorites fav ON a.id = fav.article_id JOIN users fav_user ON fav.user_id = fav_user.id"
+		conditions = append(conditions, "fav_user.username = ?")
+		args = append(args, filters.Favorited)
+	}
+	if filters.FavoritesOf > 0 {
+		joins += " JOIN favorites uf ON a.id = uf.article_id"
+		conditions = append(conditions, "uf.user_id = ?")
+		args = append(args, filters.FavoritesOf)
+	}
+
+	// Add WHERE clause if conditions exist
+	if len(conditions) > 0 {
+		joins += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	return joins, args
+}
+
+// countArticles counts the articles matching filters, ignoring pagination
+func (h *Handler) countArticles(filters models.ArticleFilters, userID int) (int, error) {
+	clause, args := articleFilterClause(filters, userID)
+	var count int
+	err := h.queryRow("queryArticles count", `
+		SELECT COUNT(DISTINCT a.id)
+		FROM articles a
+		JOIN users u ON a.author_id = u.id
+	`+clause, args...).Scan(&count)
+	return count, err
+}
+
+// queryArticles assembles the filtered, paginated article list along with the total match count
+func (h *Handler) queryArticles(filters models.ArticleFilters, userID int) ([]models.Article, int, error) {
+	// Build the base query
+	baseQuery := `
+		SELECT DISTINCT
+			a.id, a.slug, a.title, a.description, a.body, a.author_id,
+			a.created_at, a.updated_at, a.cover_image, a.version, a.comments_enabled, a.published, a.pinned,
+			u.username, u.bio, u.image,
+			COALESCE(
+				(SELECT COUNT(*) FROM favorites f WHERE f.article_id = a.id AND f.user_id = ?), 
+				0
+			) > 0 as favorited,
+			(SELECT COUNT(*) FROM favorites f WHERE f.article_id = a.id) as favorites_count
+		FROM articles a
+		JOIN users u ON a.author_id = u.id
+	`
+
+	clause, clauseArgs := articleFilterClause(filters, userID)
+	baseQuery += clause
+	args := append([]interface{}{userID}, clauseArgs...)
+
+	// Add ordering and pagination. created_at has one-second resolution, so
+	// id breaks ties and keeps pages stable when articles share a timestamp.
+	// Popular ranks by favorites within the trending window first.
+	if filters.Popular {
+		baseQuery += ` ORDER BY (SELECT COUNT(*) FROM favorites rf WHERE rf.article_id = a.id AND rf.created_at > datetime('now', ?)) DESC,
+			favorites_count DESC, a.created_at DESC, a.id DESC`
+		args = append(args, fmt.Sprintf("-%d days", h.TrendingDays))
+	} else if filters.FavoritesOf > 0 {
+		baseQuery += " ORDER BY uf.created_at DESC, a.id DESC"
+	} else if filters.PinnedFirst {
+		baseQuery += " ORDER BY a.pinned DESC, a.created_at DESC, a.id DESC"
+	} else if filters.Sort == models.ArticleSortOldest {
+		baseQuery += " ORDER BY a.created_at ASC, a.id ASC"
+	} else if filters.Sort == models.ArticleSortFavorites {
+		baseQuery += " ORDER BY favorites_count DESC, a.created_at DESC, a.id DESC"
+	} else {
+		baseQuery += " ORDER BY a.created_at DESC, a.id DESC"
+	}
+	baseQuery += " LIMIT ? OFFSET ?"
+	args = append(args, filters.Limit, filters.Offset)
+
+	// Get total count
+	totalCount, err := h.countArticles(filters, userID)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	// Get articles
+	rows, err := h.query("queryArticles", baseQuery, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	var articles []models.Article
+	for rows.Next() {
+		var article models.Article
+		var authorUsername, authorBio, authorImage string
+		var favorited bool
+		var favoritesCount int
+
+		err := rows.Scan(
+			&article.ID, &article.Slug, &article.Title, &article.Description, 
+			&article.Body, &article.AuthorID, &article.CreatedAt, &article.UpdatedAt, &article.CoverImage, &article.Version, &article.CommentsEnabled, &article.Published, &article.Pinned,
+			&authorUsername, &authorBio, &authorImage,
+			&favorited, &favoritesCount,
+		)
+		if err != nil {
+			return nil, 0, err
+		}
+
+		// Check if current user follows the author
+		var following bool
+		if userID > 0 {
+			var followCount int
+			h.DB.QueryRow(`
+				SELECT COUNT(*) FROM follows 
+				WHERE follower_id = ? AND following_id = ?
+			`, userID, article.AuthorID).Scan(&followCount)
+			following = followCount > 0
+		}
+
+		// Set article fields
+		article.Favorited = favorited
+		article.FavoritesCount = favoritesCount
+		article.Author = models.Profile{
+			Username:  authorUsername,
+			Bio:       authorBio,
+			Image:     models.DisplayImage(authorImage),
+			Following: following,
+		}
+
+		// Get article tags
+		tagRows, err := h.DB.Query(`
+			SELECT t.name 
+			FROM tags t 
+			JOIN article_tags at ON t.id = at.tag_id 
+			WHERE at.article_id = ?
+			ORDER BY at.position, t.name
+		`, article.ID)
+		if err != nil {
+			return nil, 0, err
+		}
+
+		var tags []string
+		for tagRows.Next() {
+			var tagName string
+			if err := tagRows.Scan(&tagName); err != nil {
+				tagRows.Close()
+				return nil, 0, err
+			}
+			tags = append(tags, tagName)
+		}
+		tagRows.Close()
+		
+		article.TagList = tags
+		if article.TagList == nil {
+			article.TagList = make([]string, 0)
+		}
 
-// parseIntDefault parses a string to int with a default value
-func parseIntDefault(s string, defaultValue int) int {
-	if i, err := strconv.Atoi(s); err == nil {
-		return i
+		articles = append(articles, article)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	if articles == nil {
+		articles = make([]models.Article, 0)
 	}
-	return defaultValue
+
+	return articles, totalCount, nil
 }
 
 // getArticleBySlug retrieves a complete article by slug with author profile, tags, and favorite status
 func (h *Handler) getArticleBySlug(slug string, userID int) (*models.Article, error) {
+	article, err := h.getArticleBySlugWithDrafts(slug, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Drafts don't exist for anyone but their author
+	if !article.Published && article.AuthorID != userID {
+		return nil, sql.ErrNoRows
+	}
+	return article, nil
+}
+
+// getArticleBySlugWithDrafts is getArticleBySlug for callers that may see
+// other users' drafts, such as admins; they must check Published themselves
+func (h *Handler) getArticleBySlugWithDrafts(slug string, userID int) (*models.Article, error) {
 	var article models.Article
 	var authorUsername, authorBio, authorImage string
 	var favorited bool
 	var favoritesCount int
 	
 	// Query article with author details
-	err := h.DB.QueryRow(`
+	err := h.queryRow("getArticleBySlug", `
 		SELECT 
 			a.id, a.slug, a.title, a.description, a.body, a.author_id,
-			a.created_at, a.updated_at,
+			a.created_at, a.updated_at, a.cover_image, a.version, a.comments_enabled, a.published, a.pinned,
 			u.username, u.bio, u.image,
 			COALESCE(
 				(SELECT COUNT(*) FROM favorites f WHERE f.article_id = a.id AND f.user_id = ?), 
@@ -1436,7 +2716,7 @@ func (h *Handler) getArticleBySlug(slug string, userID int) (*models.Article, er
 		WHERE a.slug = ?
 	`, userID, slug).Scan(
 		&article.ID, &article.Slug, &article.Title, &article.Description, 
-		&article.Body, &article.AuthorID, &article.CreatedAt, &article.UpdatedAt,
+		&article.Body, &article.AuthorID, &article.CreatedAt, &article.UpdatedAt, &article.CoverImage, &article.Version, &article.CommentsEnabled, &article.Published, &article.Pinned,
 		&authorUsername, &authorBio, &authorImage,
 		&favorited, &favoritesCount,
 	)
@@ -1462,7 +2742,7 @@ func (h *Handler) getArticleBySlug(slug string, userID int) (*models.Article, er
 	article.Author = models.Profile{
 		Username:  authorUsername,
 		Bio:       authorBio,
-		Image:     authorImage,
+		Image:     models.DisplayImage(authorImage),
 		Following: following,
 	}
 
@@ -1472,7 +2752,7 @@ func (h *Handler) getArticleBySlug(slug string, userID int) (*models.Article, er
 		FROM tags t 
 		JOIN article_tags at ON t.id = at.tag_id 
 		WHERE at.article_id = ?
-		ORDER BY t.name
+		ORDER BY at.position, t.name
 	`, article.ID)
 	
 	if err != nil {
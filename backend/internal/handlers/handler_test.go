@@ -0,0 +1,101 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/realworld/backend/internal/database"
+	"github.com/realworld/backend/internal/middleware"
+	"github.com/realworld/backend/internal/models"
+	"github.com/realworld/backend/internal/utils"
+)
+
+// testPassword is the password of every user made by createTestUser
+const testPassword = "password123"
+
+// testPasswordHash is testPassword's hash, computed once since hashing is
+// deliberately slow
+var testPasswordHash = sync.OnceValues(func() (string, error) {
+	return utils.HashPassword(testPassword)
+})
+
+// newTestHandler returns a Handler backed by a freshly migrated database in
+// a temporary directory
+func newTestHandler(t *testing.T) *Handler {
+	t.Helper()
+	db, err := database.New(filepath.Join(t.TempDir(), "rw.db"))
+	if err != nil {
+		t.Fatalf("database.New: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	return &Handler{
+		DB:        db.DB,
+		JWTSecret: "test-secret",
+		Logger:    log.New(io.Discard, "", 0),
+	}
+}
+
+// createTestUser inserts a user with testPassword and returns them as the
+// auth middleware would
+func createTestUser(t *testing.T, h *Handler, username string) *middleware.User {
+	t.Helper()
+	hash, err := testPasswordHash()
+	if err != nil {
+		t.Fatalf("hashing password: %v", err)
+	}
+	result, err := h.DB.Exec(
+		"INSERT INTO users (username, email, password_hash) VALUES (?, ?, ?)",
+		username, username+"@example.com", hash,
+	)
+	if err != nil {
+		t.Fatalf("creating user %s: %v", username, err)
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		t.Fatalf("creating user %s: %v", username, err)
+	}
+	return &middleware.User{ID: int(id), Username: username, Email: username + "@example.com"}
+}
+
+// serve routes one request through a mux holding only handler at pattern, as
+// user when not nil, and returns the recorded response
+func serve(pattern string, handler http.HandlerFunc, method, target, body string, user *middleware.User) *httptest.ResponseRecorder {
+	mux := http.NewServeMux()
+	mux.HandleFunc(pattern, handler)
+
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	if user != nil {
+		req = req.WithContext(context.WithValue(req.Context(), middleware.UserContextKey, user))
+	}
+
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+	return rec
+}
+
+// createTestArticle publishes an article by author through CreateArticle and
+// returns its slug
+func createTestArticle(t *testing.T, h *Handler, author *middleware.User, title string) string {
+	t.Helper()
+	body := `{"article":{"title":"` + title + `","description":"d","body":"b"}}`
+	rec := serve("POST /api/articles", h.CreateArticle, "POST", "/api/articles", body, author)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("creating article %q: status %d: %s", title, rec.Code, rec.Body)
+	}
+
+	var resp models.ArticleResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("creating article %q: %v", title, err)
+	}
+	return resp.Article.Slug
+}
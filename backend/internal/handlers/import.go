@@ -0,0 +1,150 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"net/http"
+
+	"github.com/realworld/backend/internal/middleware"
+	"github.com/realworld/backend/internal/models"
+	"github.com/realworld/backend/internal/utils"
+)
+
+// ImportArticles creates a batch of articles by the current user in a single
+// transaction. Every article is validated first; if any is invalid nothing is
+// imported and the per-article results say why.
+func (h *Handler) ImportArticles(w http.ResponseWriter, r *http.Request) {
+	authUser, ok := middleware.GetUserFromContext(r.Context())
+	if !ok {
+		models.WriteErrorResponse(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	var req models.ImportArticlesRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		models.WriteErrorResponse(w, http.StatusBadRequest, "Invalid JSON format")
+		return
+	}
+
+	if errs := req.Validate(); errs != nil {
+		models.WriteErrorResponse(w, http.StatusUnprocessableEntity, errs)
+		return
+	}
+
+	tx, err := h.DB.Begin()
+	if err != nil {
+		h.Logger.Printf("Database error starting transaction: %v", err)
+		models.WriteErrorResponse(w, http.StatusInternalServerError, "Internal server error")
+		return
+	}
+	defer tx.Rollback()
+
+	// A batch counts as one creation toward the cooldown
+	if !h.beginArticleCooldown(w, tx, authUser) {
+		return
+	}
+
+	// Slugs claimed earlier in the batch count as taken too
+	claimed := make(map[string]bool)
+	slugs := slugChecker{tx: tx}
+	slugExists := func(slug string) bool {
+		return claimed[slug] || slugs.exists(slug)
+	}
+
+	results := make([]models.ImportResult, len(req.Articles))
+	invalid := false
+	for i := range req.Articles {
+		item := &req.Articles[i]
+		results[i].Index = i
+
+		errs := item.Validate()
+		if item.Slug != "" && len(errs) == 0 && slugExists(item.Slug) {
+			errs = append(errs, models.ValidationError{Field: "slug", Message: "is already taken"})
+		}
+		if len(errs) > 0 {
+			results[i].Status = models.ImportStatusInvalid
+			results[i].Errors = models.NewValidationErrorResponse(errs).Errors
+			invalid = true
+			continue
+		}
+
+		slug := item.Slug
+		if slug == "" {
+			slug = utils.GenerateUniqueSlug(item.Title, h.SlugStrategy, slugExists)
+		}
+		// A failed lookup would otherwise pass for a free slug
+		if slugs.err != nil {
+			h.Logger.Printf("Database error checking slug: %v", slugs.err)
+			models.WriteErrorResponse(w, http.StatusInternalServerError, "Internal server error")
+			return
+		}
+		claimed[slug] = true
+		results[i].Slug = slug
+
+		// Keep validating the rest so one response reports every problem
+		if invalid {
+			continue
+		}
+
+		if err := h.insertImportedArticle(tx, authUser.ID, slug, item); err != nil {
+			h.Logger.Printf("Database error importing article %d: %v", i, err)
+			models.WriteErrorResponse(w, http.StatusInternalServerError, "Internal server error")
+			return
+		}
+		results[i].Status = models.ImportStatusCreated
+	}
+
+	if invalid {
+		for i := range results {
+			if results[i].Status != models.ImportStatusInvalid {
+				results[i].Status = models.ImportStatusSkipped
+			}
+		}
+		models.WriteJSONResponse(w, http.StatusUnprocessableEntity, models.ImportArticlesResponse{Results: results})
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		h.Logger.Printf("Error committing transaction: %v", err)
+		models.WriteErrorResponse(w, http.StatusInternalServerError, "Internal server error")
+		return
+	}
+	h.invalidateTags()
+	h.invalidateArticleLists()
+
+	models.WriteJSONResponse(w, http.StatusCreated, models.ImportArticlesResponse{
+		Imported: len(results),
+		Results:  results,
+	})
+}
+
+// insertImportedArticle inserts one validated article and its tags, keeping
+// its original creation time when one was given
+func (h *Handler) insertImportedArticle(tx *sql.Tx, authorID int, slug string, item *models.ImportArticle) error {
+	// An empty cover image is stored as NULL
+	var coverImage interface{}
+	if item.CoverImage != "" {
+		coverImage = item.CoverImage
+	}
+	commentsEnabled := item.CommentsEnabled == nil || *item.CommentsEnabled
+
+	var createdAt interface{}
+	if item.CreatedAt != nil {
+		createdAt = item.CreatedAt.UTC().Format(sqliteTimeFormat)
+	}
+
+	result, err := tx.Exec(`
+		INSERT INTO articles (slug, title, description, body, author_id, cover_image, comments_enabled, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, COALESCE(?, CURRENT_TIMESTAMP), COALESCE(?, CURRENT_TIMESTAMP))
+	`, slug, item.Title, item.Description, item.Body, authorID, coverImage, commentsEnabled, createdAt, createdAt)
+	if err != nil {
+		return err
+	}
+
+	articleID, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+
+	return addArticleTags(tx, articleID, item.TagList)
+}
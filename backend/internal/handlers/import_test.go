@@ -0,0 +1,55 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/realworld/backend/internal/models"
+)
+
+func TestImportArticlesSlugs(t *testing.T) {
+	h := newTestHandler(t)
+	author := createTestUser(t, h, "writer")
+	taken := createTestArticle(t, h, author, "Taken")
+
+	rec := serve("POST /api/articles/import", h.ImportArticles, "POST", "/api/articles/import",
+		`{"articles":[{"title":"Taken","description":"d","body":"b"},{"title":"Taken","description":"d","body":"b"}]}`, author)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("status %d: %s", rec.Code, rec.Body)
+	}
+	var resp models.ImportArticlesResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decoding response: %v", err)
+	}
+	seen := map[string]bool{taken: true}
+	for _, result := range resp.Results {
+		if seen[result.Slug] {
+			t.Errorf("article %d got slug %q, which is already taken", result.Index, result.Slug)
+		}
+		seen[result.Slug] = true
+	}
+
+	rec = serve("POST /api/articles/import", h.ImportArticles, "POST", "/api/articles/import",
+		`{"articles":[{"title":"Mine","slug":"`+taken+`","description":"d","body":"b"}]}`, author)
+	if rec.Code != http.StatusUnprocessableEntity {
+		t.Errorf("importing a taken slug: status %d, want %d", rec.Code, http.StatusUnprocessableEntity)
+	}
+}
+
+func TestSlugCheckerKeepsLookupErrors(t *testing.T) {
+	h := newTestHandler(t)
+	tx, err := h.DB.Begin()
+	if err != nil {
+		t.Fatalf("Begin: %v", err)
+	}
+	tx.Rollback()
+
+	slugs := slugChecker{tx: tx}
+	if slugs.exists("anything") {
+		t.Error("exists reported a slug taken after a failed lookup")
+	}
+	if slugs.err == nil {
+		t.Error("the failed lookup's error was dropped")
+	}
+}
@@ -0,0 +1,50 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"testing"
+
+	"github.com/realworld/backend/internal/models"
+)
+
+func TestListArticlesPagesThroughTiedTimestamps(t *testing.T) {
+	h := newTestHandler(t)
+	author := createTestUser(t, h, "writer")
+
+	const total = 7
+	// Ties are broken newest first, so pages run in reverse creation order
+	want := make([]string, total)
+	for i := 0; i < total; i++ {
+		want[total-1-i] = createTestArticle(t, h, author, fmt.Sprintf("Article %d", i))
+	}
+	if _, err := h.DB.Exec("UPDATE articles SET created_at = '2024-01-01 00:00:00' WHERE author_id = ?", author.ID); err != nil {
+		t.Fatalf("tying timestamps: %v", err)
+	}
+
+	var got []string
+	for offset := 0; offset < total; offset += 2 {
+		target := fmt.Sprintf("/api/articles?author=writer&limit=2&offset=%d", offset)
+		rec := serve("GET /api/articles", h.ListArticles, "GET", target, "", nil)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("GET %s: status %d: %s", target, rec.Code, rec.Body)
+		}
+
+		var resp models.ArticlesResponse
+		if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("GET %s: %v", target, err)
+		}
+		if resp.ArticlesCount != total {
+			t.Errorf("GET %s: articlesCount %d, want %d", target, resp.ArticlesCount, total)
+		}
+		for _, article := range resp.Articles {
+			got = append(got, article.Slug)
+		}
+	}
+
+	if !slices.Equal(got, want) {
+		t.Errorf("pages returned %q, want %q", got, want)
+	}
+}
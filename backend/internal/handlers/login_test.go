@@ -0,0 +1,65 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/realworld/backend/internal/models"
+)
+
+func TestLoginValidationVersusCredentials(t *testing.T) {
+	h := newTestHandler(t)
+	createTestUser(t, h, "alice")
+
+	tests := []struct {
+		name        string
+		body        string
+		wantStatus  int
+		wantField   string
+		wantMessage string
+	}{
+		{"empty password", `{"user":{"email":"alice@example.com","password":""}}`, http.StatusUnprocessableEntity, "password", "is required"},
+		{"missing email", `{"user":{"password":"password123"}}`, http.StatusUnprocessableEntity, "email", "is required"},
+		{"wrong password", `{"user":{"email":"alice@example.com","password":"wrong-password"}}`, http.StatusUnauthorized, "body", "Invalid email or password"},
+		{"unknown email", `{"user":{"email":"bob@example.com","password":"password123"}}`, http.StatusUnauthorized, "body", "Invalid email or password"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := serve("POST /api/users/login", h.Login, "POST", "/api/users/login", tt.body, nil)
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status %d, want %d: %s", rec.Code, tt.wantStatus, rec.Body)
+			}
+
+			var resp struct {
+				Errors map[string][]string `json:"errors"`
+			}
+			if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("decoding response: %v", err)
+			}
+			if got := resp.Errors[tt.wantField]; len(got) != 1 || got[0] != tt.wantMessage {
+				t.Errorf("errors[%q] = %q, want [%q]", tt.wantField, got, tt.wantMessage)
+			}
+		})
+	}
+}
+
+func TestLoginSucceeds(t *testing.T) {
+	h := newTestHandler(t)
+	createTestUser(t, h, "alice")
+
+	rec := serve("POST /api/users/login", h.Login, "POST", "/api/users/login",
+		`{"user":{"email":"alice@example.com","password":"`+testPassword+`"}}`, nil)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status %d, want %d: %s", rec.Code, http.StatusOK, rec.Body)
+	}
+
+	var resp models.UserResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decoding response: %v", err)
+	}
+	if resp.User.Username != "alice" || resp.User.Token == "" {
+		t.Errorf("got user %q with token %q, want alice with a token", resp.User.Username, resp.User.Token)
+	}
+}
@@ -0,0 +1,129 @@
+package handlers
+
+import (
+	"encoding/json"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"net/http"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+type openAPIDocument struct {
+	OpenAPI string `json:"openapi"`
+	Paths   map[string]map[string]struct {
+		OperationID string `json:"operationId"`
+		Parameters  []struct {
+			Name string `json:"name"`
+			In   string `json:"in"`
+		} `json:"parameters"`
+	} `json:"paths"`
+}
+
+// registeredRoutes returns the "METHOD /path" patterns passed to
+// Handle and HandleFunc in cmd/server, including ones registered only
+// under some configurations
+func registeredRoutes(t *testing.T) []string {
+	t.Helper()
+	file, err := parser.ParseFile(token.NewFileSet(), "../../cmd/server/main.go", nil, 0)
+	if err != nil {
+		t.Fatalf("parsing cmd/server: %v", err)
+	}
+
+	var routes []string
+	ast.Inspect(file, func(n ast.Node) bool {
+		call, ok := n.(*ast.CallExpr)
+		if !ok || len(call.Args) == 0 {
+			return true
+		}
+		sel, ok := call.Fun.(*ast.SelectorExpr)
+		if !ok || (sel.Sel.Name != "Handle" && sel.Sel.Name != "HandleFunc") {
+			return true
+		}
+		lit, ok := call.Args[0].(*ast.BasicLit)
+		if !ok || lit.Kind != token.STRING {
+			return true
+		}
+		pattern, err := strconv.Unquote(lit.Value)
+		if err == nil && strings.Contains(pattern, " ") {
+			routes = append(routes, pattern)
+		}
+		return true
+	})
+	return routes
+}
+
+func TestOpenAPISpecMatchesRoutes(t *testing.T) {
+	var doc openAPIDocument
+	if err := json.Unmarshal(openAPISpec, &doc); err != nil {
+		t.Fatalf("openapi.json is not valid JSON: %v", err)
+	}
+	if !strings.HasPrefix(doc.OpenAPI, "3.") {
+		t.Errorf("openapi = %q, want 3.x", doc.OpenAPI)
+	}
+
+	pathParam := regexp.MustCompile(`{(\w+)}`)
+	operationIDs := make(map[string]string)
+	var documented []string
+	for path, operations := range doc.Paths {
+		for method, op := range operations {
+			route := strings.ToUpper(method) + " " + path
+			documented = append(documented, route)
+
+			if other, ok := operationIDs[op.OperationID]; ok {
+				t.Errorf("%s reuses operationId %q from %s", route, op.OperationID, other)
+			}
+			operationIDs[op.OperationID] = route
+
+			var declared []string
+			for _, p := range op.Parameters {
+				if p.In == "path" {
+					declared = append(declared, p.Name)
+				}
+			}
+			var want []string
+			for _, m := range pathParam.FindAllStringSubmatch(path, -1) {
+				want = append(want, m[1])
+			}
+			slices.Sort(declared)
+			slices.Sort(want)
+			if !slices.Equal(declared, want) {
+				t.Errorf("%s declares path parameters %q, want %q", route, declared, want)
+			}
+		}
+	}
+
+	routes := registeredRoutes(t)
+	if len(routes) == 0 {
+		t.Fatal("found no routes in cmd/server")
+	}
+	for _, route := range routes {
+		if !slices.Contains(documented, route) {
+			t.Errorf("%s is registered but missing from openapi.json", route)
+		}
+	}
+	for _, route := range documented {
+		if !slices.Contains(routes, route) {
+			t.Errorf("%s is in openapi.json but not registered", route)
+		}
+	}
+}
+
+func TestOpenAPIServesSpec(t *testing.T) {
+	h := newTestHandler(t)
+	rec := serve("GET /api/openapi.json", h.OpenAPI, "GET", "/api/openapi.json", "", nil)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status %d, want %d", rec.Code, http.StatusOK)
+	}
+	if got := rec.Header().Get("Content-Type"); !strings.HasPrefix(got, "application/json") {
+		t.Errorf("Content-Type = %q, want application/json", got)
+	}
+	if !json.Valid(rec.Body.Bytes()) {
+		t.Error("response body is not valid JSON")
+	}
+}
@@ -0,0 +1,52 @@
+package handlers
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/realworld/backend/internal/models"
+)
+
+func TestParsePagination(t *testing.T) {
+	tests := []struct {
+		query      string
+		wantLimit  int
+		wantOffset int
+		wantErrs   models.ValidationErrors
+	}{
+		{"", defaultPageLimit, 0, nil},
+		{"limit=5&offset=10", 5, 10, nil},
+		{"limit=500", maxPageLimit, 0, nil},
+		{"limit=0", defaultPageLimit, 0, models.ValidationErrors{{Field: "limit", Message: "must be at least 1"}}},
+		{"limit=-1", defaultPageLimit, 0, models.ValidationErrors{{Field: "limit", Message: "must be at least 1"}}},
+		{"limit=abc", defaultPageLimit, 0, models.ValidationErrors{{Field: "limit", Message: "must be a number"}}},
+		{"offset=-1", defaultPageLimit, 0, models.ValidationErrors{{Field: "offset", Message: "must not be negative"}}},
+		{"offset=abc", defaultPageLimit, 0, models.ValidationErrors{{Field: "offset", Message: "must be a number"}}},
+		{"limit=abc&offset=-1", defaultPageLimit, 0, models.ValidationErrors{
+			{Field: "limit", Message: "must be a number"},
+			{Field: "offset", Message: "must not be negative"},
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			query, err := url.ParseQuery(tt.query)
+			if err != nil {
+				t.Fatalf("ParseQuery(%q): %v", tt.query, err)
+			}
+
+			limit, offset, errs := parsePagination(query)
+			if limit != tt.wantLimit || offset != tt.wantOffset {
+				t.Errorf("got limit %d offset %d, want limit %d offset %d", limit, offset, tt.wantLimit, tt.wantOffset)
+			}
+			if len(errs) != len(tt.wantErrs) {
+				t.Fatalf("got errors %v, want %v", errs, tt.wantErrs)
+			}
+			for i := range errs {
+				if errs[i] != tt.wantErrs[i] {
+					t.Errorf("error %d = %v, want %v", i, errs[i], tt.wantErrs[i])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,80 @@
+package handlers
+
+import (
+	"database/sql"
+	"net/http"
+
+	"github.com/realworld/backend/internal/middleware"
+	"github.com/realworld/backend/internal/models"
+)
+
+// PinArticle pins one of the current user's articles to the top of their
+// profile listing, unpinning whichever was pinned before
+func (h *Handler) PinArticle(w http.ResponseWriter, r *http.Request) {
+	h.setPinned(w, r, true)
+}
+
+// UnpinArticle unpins one of the current user's articles; unpinning an
+// article that isn't pinned is a no-op
+func (h *Handler) UnpinArticle(w http.ResponseWriter, r *http.Request) {
+	h.setPinned(w, r, false)
+}
+
+// setPinned sets the pinned flag on the author's own article and writes it
+func (h *Handler) setPinned(w http.ResponseWriter, r *http.Request, pinned bool) {
+	authUser, ok := middleware.GetUserFromContext(r.Context())
+	if !ok {
+		models.WriteErrorResponse(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	slug := r.PathValue("slug")
+	article, err := h.getArticleBySlug(slug, authUser.ID)
+	if err == sql.ErrNoRows {
+		models.WriteErrorResponse(w, http.StatusNotFound, "Article not found")
+		return
+	}
+	if err != nil {
+		h.Logger.Printf("Database error getting article: %v", err)
+		models.WriteErrorResponse(w, http.StatusInternalServerError, "Internal server error")
+		return
+	}
+
+	if article.AuthorID != authUser.ID {
+		models.WriteErrorResponse(w, http.StatusForbidden, "You can only pin your own articles")
+		return
+	}
+
+	tx, err := h.DB.Begin()
+	if err != nil {
+		h.Logger.Printf("Database error starting transaction: %v", err)
+		models.WriteErrorResponse(w, http.StatusInternalServerError, "Internal server error")
+		return
+	}
+	defer tx.Rollback()
+
+	// One pinned article per author; the unique index backs this up
+	if pinned {
+		if _, err := tx.Exec("UPDATE articles SET pinned = 0 WHERE author_id = ? AND pinned = 1 AND id != ?", authUser.ID, article.ID); err != nil {
+			h.Logger.Printf("Database error unpinning articles: %v", err)
+			models.WriteErrorResponse(w, http.StatusInternalServerError, "Internal server error")
+			return
+		}
+	}
+	if _, err := tx.Exec("UPDATE articles SET pinned = ? WHERE id = ?", pinned, article.ID); err != nil {
+		h.Logger.Printf("Database error pinning article: %v", err)
+		models.WriteErrorResponse(w, http.StatusInternalServerError, "Internal server error")
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		h.Logger.Printf("Error committing transaction: %v", err)
+		models.WriteErrorResponse(w, http.StatusInternalServerError, "Internal server error")
+		return
+	}
+
+	h.invalidateArticleLists()
+
+	article.Pinned = pinned
+	models.WriteJSONResponse(w, http.StatusOK, models.ArticleResponse{Article: *article})
+}
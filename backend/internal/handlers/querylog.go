@@ -0,0 +1,82 @@
+package handlers
+
+import (
+	"database/sql"
+	"strings"
+	"time"
+)
+
+// query runs a query, logging it when it exceeds the slow query threshold.
+// The label names the call site so slow queries can be traced to a handler.
+// go-sqlite3 only prepares and binds in Query and runs the statement as rows
+// are read, so the time is taken when the rows are closed.
+func (h *Handler) query(label, query string, args ...interface{}) (*timedRows, error) {
+	start := time.Now()
+	rows, err := h.DB.Query(query, args...)
+	if err != nil {
+		h.logIfSlow(label, query, args, time.Since(start))
+		return nil, err
+	}
+	return &timedRows{Rows: rows, h: h, label: label, query: query, args: args, start: start}, nil
+}
+
+// queryRow runs a single-row query, logging it when it exceeds the slow
+// query threshold. As with query, the time is taken once Scan has run it.
+func (h *Handler) queryRow(label, query string, args ...interface{}) *timedRow {
+	start := time.Now()
+	return &timedRow{row: h.DB.QueryRow(query, args...), h: h, label: label, query: query, args: args, start: start}
+}
+
+// timedRows logs its query on Close if it was slow
+type timedRows struct {
+	*sql.Rows
+	h      *Handler
+	label  string
+	query  string
+	args   []interface{}
+	start  time.Time
+	logged bool
+}
+
+// Close closes the rows and, the first time, checks how long the query took
+func (r *timedRows) Close() error {
+	err := r.Rows.Close()
+	if !r.logged {
+		r.logged = true
+		r.h.logIfSlow(r.label, r.query, r.args, time.Since(r.start))
+	}
+	return err
+}
+
+// timedRow logs its query on Scan if it was slow
+type timedRow struct {
+	row   *sql.Row
+	h     *Handler
+	label string
+	query string
+	args  []interface{}
+	start time.Time
+}
+
+// Scan copies the row into dest like sql.Row.Scan
+func (r *timedRow) Scan(dest ...interface{}) error {
+	err := r.row.Scan(dest...)
+	r.h.logIfSlow(r.label, r.query, r.args, time.Since(r.start))
+	return err
+}
+
+// logIfSlow logs a query that took at least SlowQueryThreshold. Parameter
+// values are left out unless SlowQueryLogArgs is set, since they can hold
+// user data.
+func (h *Handler) logIfSlow(label, query string, args []interface{}, elapsed time.Duration) {
+	if h.SlowQueryThreshold <= 0 || elapsed < h.SlowQueryThreshold {
+		return
+	}
+
+	query = strings.Join(strings.Fields(query), " ")
+	if h.SlowQueryLogArgs {
+		h.Logger.Printf("Slow query (%s) took %v: %s %v", label, elapsed, query, args)
+	} else {
+		h.Logger.Printf("Slow query (%s) took %v: %s [%d args redacted]", label, elapsed, query, len(args))
+	}
+}
@@ -0,0 +1,77 @@
+package handlers
+
+import (
+	"bytes"
+	"log"
+	"strings"
+	"testing"
+	"time"
+)
+
+// slowQuery counts through a recursive CTE; SQLite does the work while the
+// row is read, not when the query is issued
+const slowQuery = `
+	WITH RECURSIVE c(x) AS (SELECT 1 UNION ALL SELECT x + 1 FROM c WHERE x < 500000)
+	SELECT COUNT(*) FROM c`
+
+func TestSlowQueryLogging(t *testing.T) {
+	tests := []struct {
+		name string
+		run  func(h *Handler) error
+	}{
+		{"query", func(h *Handler) error {
+			rows, err := h.query("slow test", slowQuery)
+			if err != nil {
+				return err
+			}
+			defer rows.Close()
+			for rows.Next() {
+				var n int
+				if err := rows.Scan(&n); err != nil {
+					return err
+				}
+			}
+			return rows.Err()
+		}},
+		{"queryRow", func(h *Handler) error {
+			var n int
+			return h.queryRow("slow test", slowQuery).Scan(&n)
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newTestHandler(t)
+			var logs bytes.Buffer
+			h.Logger = log.New(&logs, "", 0)
+			h.SlowQueryThreshold = 5 * time.Millisecond
+
+			start := time.Now()
+			if err := tt.run(h); err != nil {
+				t.Fatalf("running query: %v", err)
+			}
+			if elapsed := time.Since(start); elapsed < h.SlowQueryThreshold {
+				t.Skipf("query took only %v; too fast to test the threshold", elapsed)
+			}
+
+			if !strings.Contains(logs.String(), "Slow query (slow test)") {
+				t.Errorf("slow query not logged; log: %q", logs.String())
+			}
+		})
+	}
+}
+
+func TestFastQueryNotLogged(t *testing.T) {
+	h := newTestHandler(t)
+	var logs bytes.Buffer
+	h.Logger = log.New(&logs, "", 0)
+	h.SlowQueryThreshold = time.Second
+
+	var n int
+	if err := h.queryRow("fast test", "SELECT 1").Scan(&n); err != nil {
+		t.Fatalf("running query: %v", err)
+	}
+	if logs.Len() > 0 {
+		t.Errorf("fast query logged: %q", logs.String())
+	}
+}
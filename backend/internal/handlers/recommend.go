@@ -0,0 +1,235 @@
+package handlers
+
+import (
+	"strings"
+
+	"github.com/realworld/backend/internal/models"
+)
+
+// recommendedFilter selects recent published articles sharing a tag with the
+// user's favorites, skipping the user's own, favorited, followed-author, and
+// blocked-author articles
+var recommendedFilter = `
+	a.published = 1
+	AND a.author_id != ?
+	AND ` + excludeBlockedAuthors("a.author_id") + `
+	AND a.author_id NOT IN (SELECT following_id FROM follows WHERE follower_id = ?)
+	AND a.id NOT IN (SELECT article_id FROM favorites WHERE user_id = ?)
+	AND a.id IN (
+		SELECT at.article_id FROM article_tags at
+		WHERE at.tag_id IN (
+			SELECT fat.tag_id FROM article_tags fat
+			JOIN favorites fav ON fav.article_id = fat.article_id
+			WHERE fav.user_id = ?
+		)
+	)`
+
+// maxRecommendationCandidates bounds how many recommendations are ranked in
+// memory when RecommendationsPerTag is set
+const maxRecommendationCandidates = 500
+
+// queryRecommendedArticles returns a page of tag-based recommendations for the
+// user along with the total number available. With RecommendationsPerTag set,
+// the newest candidates are capped per tag before paging, so the total counts
+// only those kept.
+func (h *Handler) queryRecommendedArticles(userID, limit, offset int) ([]models.Article, int, error) {
+	filterArgs := []interface{}{userID, userID, userID, userID, userID}
+
+	if h.RecommendationsPerTag > 0 {
+		candidates, err := h.recommendationCandidates(filterArgs)
+		if err != nil {
+			return nil, 0, err
+		}
+		candidates = limitPerTag(candidates, h.RecommendationsPerTag)
+
+		total := len(candidates)
+		offset = min(offset, total)
+		page := candidates[offset:min(offset+max(limit, 0), total)]
+		if len(page) == 0 {
+			return make([]models.Article, 0), total, nil
+		}
+
+		// Only the returned page is loaded in full
+		ids := make([]interface{}, len(page))
+		for i, candidate := range page {
+			ids[i] = candidate.ID
+		}
+		where := "a.id IN (" + strings.TrimSuffix(strings.Repeat("?,", len(ids)), ",") + ")"
+		articles, err := h.scanRecommendedArticles(where, ids, len(ids), 0)
+		if err != nil {
+			return nil, 0, err
+		}
+		return articles, total, nil
+	}
+
+	var totalCount int
+	if err := h.DB.QueryRow(`SELECT COUNT(*) FROM articles a WHERE `+recommendedFilter, filterArgs...).Scan(&totalCount); err != nil {
+		return nil, 0, err
+	}
+
+	if limit <= 0 {
+		return make([]models.Article, 0), totalCount, nil
+	}
+
+	articles, err := h.scanRecommendedArticles(recommendedFilter, filterArgs, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	return articles, totalCount, nil
+}
+
+// recommendationCandidates returns the IDs and tags of the newest
+// maxRecommendationCandidates recommendations, enough for limitPerTag to
+// rank them
+func (h *Handler) recommendationCandidates(filterArgs []interface{}) ([]models.Article, error) {
+	rows, err := h.DB.Query(`
+		SELECT a.id FROM articles a
+		WHERE `+recommendedFilter+`
+		ORDER BY a.created_at DESC, a.id DESC
+		LIMIT ?
+	`, append(filterArgs, maxRecommendationCandidates)...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	candidates := make([]models.Article, 0)
+	for rows.Next() {
+		var candidate models.Article
+		if err := rows.Scan(&candidate.ID); err != nil {
+			return nil, err
+		}
+		candidates = append(candidates, candidate)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if err := h.loadArticleTags(candidates); err != nil {
+		return nil, err
+	}
+	return candidates, nil
+}
+
+// scanRecommendedArticles loads a page of the recommendations matching where,
+// newest first, with their tags
+func (h *Handler) scanRecommendedArticles(where string, args []interface{}, limit, offset int) ([]models.Article, error) {
+	rows, err := h.DB.Query(`
+		SELECT
+			a.id, a.slug, a.title, a.description, a.body, a.author_id,
+			a.created_at, a.updated_at, a.cover_image, a.version, a.comments_enabled, a.published, a.pinned,
+			u.username, u.bio, u.image,
+			(SELECT COUNT(*) FROM favorites f WHERE f.article_id = a.id) as favorites_count
+		FROM articles a
+		JOIN users u ON a.author_id = u.id
+		WHERE `+where+`
+		ORDER BY a.created_at DESC, a.id DESC
+		LIMIT ? OFFSET ?
+	`, append(args, limit, offset)...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	articles := make([]models.Article, 0)
+	for rows.Next() {
+		var article models.Article
+		var authorUsername, authorBio, authorImage string
+
+		err := rows.Scan(
+			&article.ID, &article.Slug, &article.Title, &article.Description,
+			&article.Body, &article.AuthorID, &article.CreatedAt, &article.UpdatedAt, &article.CoverImage, &article.Version, &article.CommentsEnabled, &article.Published, &article.Pinned,
+			&authorUsername, &authorBio, &authorImage,
+			&article.FavoritesCount,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		// Recommendations are never favorited or by followed authors
+		article.Author = models.Profile{
+			Username: authorUsername,
+			Bio:      authorBio,
+			Image:    models.DisplayImage(authorImage),
+		}
+		article.Source = models.ArticleSourceRecommended
+		articles = append(articles, article)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// Load tags once the result set is closed
+	if err := h.loadArticleTags(articles); err != nil {
+		return nil, err
+	}
+	return articles, nil
+}
+
+// limitPerTag walks articles in their ranked order and keeps each one only
+// while every tag it carries is on fewer than perTag articles already kept,
+// so the top-ranked articles of each tag survive and a prolific tag can't
+// crowd out the rest
+func limitPerTag(articles []models.Article, perTag int) []models.Article {
+	counts := make(map[string]int)
+	kept := make([]models.Article, 0, len(articles))
+	for _, article := range articles {
+		full := false
+		for _, tag := range article.TagList {
+			if counts[tag] >= perTag {
+				full = true
+				break
+			}
+		}
+		if full {
+			continue
+		}
+
+		for _, tag := range article.TagList {
+			counts[tag]++
+		}
+		kept = append(kept, article)
+	}
+	return kept
+}
+
+// loadArticleTags fills in the articles' tag lists with one query, each in
+// the order the author gave them, falling back to name order for tags that
+// share a position
+func (h *Handler) loadArticleTags(articles []models.Article) error {
+	if len(articles) == 0 {
+		return nil
+	}
+
+	byID := make(map[int]*models.Article, len(articles))
+	ids := make([]interface{}, len(articles))
+	for i := range articles {
+		articles[i].TagList = make([]string, 0)
+		byID[articles[i].ID] = &articles[i]
+		ids[i] = articles[i].ID
+	}
+
+	rows, err := h.DB.Query(`
+		SELECT at.article_id, t.name
+		FROM tags t
+		JOIN article_tags at ON t.id = at.tag_id
+		WHERE at.article_id IN (`+strings.TrimSuffix(strings.Repeat("?,", len(ids)), ",")+`)
+		ORDER BY at.article_id, at.position, t.name
+	`, ids...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var articleID int
+		var name string
+		if err := rows.Scan(&articleID, &name); err != nil {
+			return err
+		}
+		if article := byID[articleID]; article != nil {
+			article.TagList = append(article.TagList, name)
+		}
+	}
+	return rows.Err()
+}
@@ -0,0 +1,77 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"testing"
+
+	"github.com/realworld/backend/internal/middleware"
+	"github.com/realworld/backend/internal/models"
+)
+
+func createTaggedArticle(t *testing.T, h *Handler, author *middleware.User, title, tags string) string {
+	t.Helper()
+	body := fmt.Sprintf(`{"article":{"title":%q,"description":"d","body":"b","tagList":%s}}`, title, tags)
+	rec := serve("POST /api/articles", h.CreateArticle, "POST", "/api/articles", body, author)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("creating article %q: status %d: %s", title, rec.Code, rec.Body)
+	}
+	var resp models.ArticleResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("creating article %q: %v", title, err)
+	}
+	return resp.Article.Slug
+}
+
+func TestDiscoverFeedCapsRecommendationsPerTag(t *testing.T) {
+	h := newTestHandler(t)
+	reader := createTestUser(t, h, "reader")
+	curator := createTestUser(t, h, "curator")
+	writer := createTestUser(t, h, "writer")
+
+	liked := createTaggedArticle(t, h, curator, "Liked", `["gopher","crab"]`)
+	if rec := serve("POST /api/articles/{slug}/favorite", h.FavoriteArticle, "POST", "/api/articles/"+liked+"/favorite", "", reader); rec.Code != http.StatusOK {
+		t.Fatalf("favoriting: status %d: %s", rec.Code, rec.Body)
+	}
+
+	for i := 0; i < 4; i++ {
+		createTaggedArticle(t, h, writer, fmt.Sprintf("Gopher %d", i), `["gopher"]`)
+	}
+	// Newest first, so these two come before every gopher article
+	createTaggedArticle(t, h, writer, "Crab 0", `["crab","abalone"]`)
+	createTaggedArticle(t, h, writer, "Crab 1", `["crab","abalone"]`)
+
+	h.RecommendationsPerTag = 2
+
+	var all []models.Article
+	for _, page := range []string{"limit=3", "limit=3&offset=3"} {
+		rec := serve("GET /api/articles/feed", h.GetFeed, "GET", "/api/articles/feed?discover=true&"+page, "", reader)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("%s: status %d: %s", page, rec.Code, rec.Body)
+		}
+		var resp models.ArticlesResponse
+		if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("%s: %v", page, err)
+		}
+		if resp.ArticlesCount != 4 {
+			t.Errorf("%s: articlesCount %d, want 4", page, resp.ArticlesCount)
+		}
+		all = append(all, resp.Articles...)
+	}
+
+	var titles []string
+	for _, article := range all {
+		titles = append(titles, article.Title)
+		if article.Source != models.ArticleSourceRecommended || article.Body != "b" {
+			t.Errorf("%s: source %q, body %q, want a full recommended article", article.Title, article.Source, article.Body)
+		}
+	}
+	if want := []string{"Crab 1", "Crab 0", "Gopher 3", "Gopher 2"}; !slices.Equal(titles, want) {
+		t.Errorf("recommended %q, want %q", titles, want)
+	}
+	if len(all) > 0 && !slices.Equal(all[0].TagList, []string{"crab", "abalone"}) {
+		t.Errorf("tagList %q, want the author's order", all[0].TagList)
+	}
+}
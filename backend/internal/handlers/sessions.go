@@ -0,0 +1,145 @@
+package handlers
+
+import (
+	"database/sql"
+	"net/http"
+	"time"
+
+	"github.com/realworld/backend/internal/models"
+	"github.com/realworld/backend/internal/utils"
+)
+
+// sessionTouchInterval limits how often a session's last-seen time is
+// written, so busy clients don't cause a write per request
+const sessionTouchInterval = time.Minute
+
+// SessionActive implements middleware.SessionChecker. Sessions without a row
+// predate session tracking and stay usable until their tokens expire.
+func (h *Handler) SessionActive(sessionID string, userID int) (bool, error) {
+	var ownerID int
+	var revoked bool
+	var lastSeen models.Timestamp
+	err := h.DB.QueryRow(`
+		SELECT user_id, revoked_at IS NOT NULL, last_seen_at
+		FROM sessions WHERE id = ?
+	`, sessionID).Scan(&ownerID, &revoked, &lastSeen)
+	if err == sql.ErrNoRows {
+		return true, nil
+	}
+	if err != nil {
+		h.Logger.Printf("Database error checking session: %v", err)
+		return false, err
+	}
+
+	if revoked || ownerID != userID {
+		return false, nil
+	}
+
+	// A failure to record the visit is logged but doesn't fail the request
+	if time.Since(lastSeen.Time) >= sessionTouchInterval {
+		if _, err := h.DB.Exec("UPDATE sessions SET last_seen_at = CURRENT_TIMESTAMP WHERE id = ?", sessionID); err != nil {
+			h.Logger.Printf("Database error touching session: %v", err)
+		}
+	}
+	return true, nil
+}
+
+// issueToken issues a JWT within a session, starting a new one when
+// sessionID is empty, and records it so it can be listed and revoked
+func (h *Handler) issueToken(sessionID string, userID int, username string) (string, error) {
+	if sessionID == "" {
+		sessionID = utils.NewSessionID()
+	}
+
+	token, err := utils.GenerateToken(userID, username, h.JWTSecret, sessionID)
+	if err != nil {
+		return "", err
+	}
+
+	// Refreshing extends the session to its newest token's expiry
+	expiresAt := time.Now().Add(utils.TokenLifetime).UTC().Format(sqliteTimeFormat)
+	if _, err := h.DB.Exec(`
+		INSERT INTO sessions (id, user_id, expires_at) VALUES (?, ?, ?)
+		ON CONFLICT (id) DO UPDATE SET expires_at = excluded.expires_at
+		WHERE sessions.user_id = excluded.user_id
+	`, sessionID, userID, expiresAt); err != nil {
+		return "", err
+	}
+
+	// Sessions whose tokens have all expired are of no further use
+	if _, err := h.DB.Exec("DELETE FROM sessions WHERE user_id = ? AND expires_at < CURRENT_TIMESTAMP", userID); err != nil {
+		h.Logger.Printf("Database error pruning sessions: %v", err)
+	}
+
+	return token, nil
+}
+
+// ListSessions lists the current user's unexpired, unrevoked sessions
+func (h *Handler) ListSessions(w http.ResponseWriter, r *http.Request) {
+	authUser, ok := requireSessionAuth(w, r, "Sessions can't be managed with an API key")
+	if !ok {
+		return
+	}
+
+	rows, err := h.DB.Query(`
+		SELECT id, created_at, last_seen_at, expires_at
+		FROM sessions
+		WHERE user_id = ? AND revoked_at IS NULL AND expires_at >= CURRENT_TIMESTAMP
+		ORDER BY last_seen_at DESC, created_at DESC
+	`, authUser.ID)
+	if err != nil {
+		h.Logger.Printf("Database error listing sessions: %v", err)
+		models.WriteErrorResponse(w, http.StatusInternalServerError, "Internal server error")
+		return
+	}
+	defer rows.Close()
+
+	sessions := make([]models.Session, 0)
+	for rows.Next() {
+		var session models.Session
+		if err := rows.Scan(&session.ID, &session.CreatedAt, &session.LastSeenAt, &session.ExpiresAt); err != nil {
+			h.Logger.Printf("Error scanning session row: %v", err)
+			models.WriteErrorResponse(w, http.StatusInternalServerError, "Internal server error")
+			return
+		}
+		session.Current = session.ID == authUser.SessionID
+		sessions = append(sessions, session)
+	}
+	if err := rows.Err(); err != nil {
+		h.Logger.Printf("Database error listing sessions: %v", err)
+		models.WriteErrorResponse(w, http.StatusInternalServerError, "Internal server error")
+		return
+	}
+
+	models.WriteJSONResponse(w, http.StatusOK, models.SessionsResponse{Sessions: sessions})
+}
+
+// RevokeSession revokes one of the current user's sessions, rejecting every
+// token refreshed from it. Revoking the current session signs the caller out.
+func (h *Handler) RevokeSession(w http.ResponseWriter, r *http.Request) {
+	authUser, ok := requireSessionAuth(w, r, "Sessions can't be managed with an API key")
+	if !ok {
+		return
+	}
+
+	// Scoping the update to the user makes other users' sessions look missing
+	result, err := h.DB.Exec(`
+		UPDATE sessions SET revoked_at = CURRENT_TIMESTAMP
+		WHERE id = ? AND user_id = ? AND revoked_at IS NULL AND expires_at >= CURRENT_TIMESTAMP
+	`, r.PathValue("id"), authUser.ID)
+	if err != nil {
+		h.Logger.Printf("Database error revoking session: %v", err)
+		models.WriteErrorResponse(w, http.StatusInternalServerError, "Internal server error")
+		return
+	}
+
+	if revoked, _ := result.RowsAffected(); revoked == 0 {
+		models.WriteErrorResponse(w, http.StatusNotFound, "Session not found")
+		return
+	}
+
+	// Return 200 OK with empty response
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte("{}"))
+}
@@ -0,0 +1,49 @@
+package handlers
+
+import (
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/realworld/backend/internal/models"
+)
+
+// statsCache holds the last computed totals so the aggregate queries don't
+// run on every hit
+type statsCache struct {
+	mu      sync.Mutex
+	stats   models.Stats
+	expires time.Time
+}
+
+// GetStats returns site-wide totals, recomputed lazily once the cached
+// copy is older than StatsCacheTTL
+func (h *Handler) GetStats(w http.ResponseWriter, r *http.Request) {
+	h.stats.mu.Lock()
+	defer h.stats.mu.Unlock()
+
+	// Holding the lock while refreshing means concurrent requests wait for
+	// one refresh instead of all running the queries
+	if now := time.Now(); !now.Before(h.stats.expires) {
+		var stats models.Stats
+		err := h.DB.QueryRow(`
+			SELECT
+				(SELECT COUNT(*) FROM users),
+				(SELECT COUNT(*) FROM articles WHERE published = 1),
+				(SELECT COUNT(*) FROM comments WHERE deleted_at IS NULL),
+				(SELECT COUNT(*) FROM tags),
+				(SELECT COUNT(*) FROM articles WHERE published = 1 AND created_at > datetime('now', '-1 day'))
+		`).Scan(&stats.Users, &stats.Articles, &stats.Comments, &stats.Tags, &stats.ArticlesLast24h)
+		if err != nil {
+			h.Logger.Printf("Database error computing stats: %v", err)
+			models.WriteErrorResponse(w, http.StatusInternalServerError, "Internal server error")
+			return
+		}
+		stats.GeneratedAt = models.NewTimestamp(now)
+
+		h.stats.stats = stats
+		h.stats.expires = now.Add(h.StatsCacheTTL)
+	}
+
+	models.WriteJSONResponse(w, http.StatusOK, models.StatsResponse{Stats: h.stats.stats})
+}
@@ -0,0 +1,91 @@
+package handlers
+
+import (
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/realworld/backend/internal/models"
+)
+
+// tagsCache holds the full ranked tag list GetTags pages through. It keeps a
+// single list, so its size is bounded by the number of tags in use.
+type tagsCache struct {
+	mu      sync.Mutex
+	tags    []string
+	expires time.Time
+}
+
+// GetTags lists the tags used by published articles, most used first, a page
+// at a time; without limit and offset it returns the top defaultPageLimit.
+// The ranking is served from memory for TagsCacheTTL, or until an article
+// write invalidates it.
+func (h *Handler) GetTags(w http.ResponseWriter, r *http.Request) {
+	limit, offset, errs := parsePagination(r.URL.Query())
+	if errs != nil {
+		models.WriteErrorResponse(w, http.StatusUnprocessableEntity, errs)
+		return
+	}
+	if h.offsetTooDeep(w, offset) {
+		return
+	}
+
+	h.tags.mu.Lock()
+	defer h.tags.mu.Unlock()
+
+	if now := time.Now(); !now.Before(h.tags.expires) {
+		tags, err := h.queryTags()
+		if err != nil {
+			h.Logger.Printf("Database error listing tags: %v", err)
+			models.WriteErrorResponse(w, http.StatusInternalServerError, "Internal server error")
+			return
+		}
+
+		h.tags.tags = tags
+		h.tags.expires = now.Add(h.TagsCacheTTL)
+	}
+
+	tags := h.tags.tags
+	total := len(tags)
+	if offset > total {
+		offset = total
+	}
+	tags = tags[offset:min(offset+limit, total)]
+
+	models.WriteJSONResponse(w, http.StatusOK, models.TagsPageResponse{Tags: tags, TagsCount: total})
+}
+
+// invalidateTags makes the next GetTags reload the tag list; call it after
+// anything that can change which tags published articles use
+func (h *Handler) invalidateTags() {
+	h.tags.mu.Lock()
+	h.tags.expires = time.Time{}
+	h.tags.mu.Unlock()
+}
+
+// queryTags returns the names of tags on at least one published article,
+// ordered by how many they are on and then by name
+func (h *Handler) queryTags() ([]string, error) {
+	rows, err := h.DB.Query(`
+		SELECT t.name
+		FROM tags t
+		JOIN article_tags at ON at.tag_id = t.id
+		JOIN articles a ON a.id = at.article_id AND a.published = 1
+		GROUP BY t.id
+		ORDER BY COUNT(*) DESC, t.name
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tags := make([]string, 0)
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		tags = append(tags, name)
+	}
+	return tags, rows.Err()
+}
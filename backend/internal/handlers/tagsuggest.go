@@ -0,0 +1,61 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/realworld/backend/internal/models"
+)
+
+// likeEscaper escapes LIKE wildcards so a prefix matches literally
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// SuggestTags returns tags on published articles starting with ?q=,
+// case-insensitively, most used first. Prefixes shorter than TagSuggestMinPrefix get an empty
+// list rather than an error so editors can call it on every keystroke.
+func (h *Handler) SuggestTags(w http.ResponseWriter, r *http.Request) {
+	prefix := strings.TrimSpace(r.URL.Query().Get("q"))
+	if utf8.RuneCountInString(prefix) < h.TagSuggestMinPrefix || h.TagSuggestMaxResults <= 0 {
+		models.WriteJSONResponse(w, http.StatusOK, models.TagsResponse{Tags: make([]string, 0)})
+		return
+	}
+
+	// tags.name is NOCASE, so its index serves the case-insensitive LIKE and
+	// only matching tags are counted. As in GetTags, tags used only on drafts
+	// or no longer used at all aren't suggested.
+	rows, err := h.DB.Query(`
+		SELECT t.name
+		FROM tags t
+		JOIN article_tags at ON at.tag_id = t.id
+		JOIN articles a ON a.id = at.article_id AND a.published = 1
+		WHERE t.name LIKE ? ESCAPE '\'
+		GROUP BY t.id
+		ORDER BY COUNT(*) DESC, t.name
+		LIMIT ?
+	`, likeEscaper.Replace(prefix)+"%", h.TagSuggestMaxResults)
+	if err != nil {
+		h.Logger.Printf("Database error suggesting tags: %v", err)
+		models.WriteErrorResponse(w, http.StatusInternalServerError, "Internal server error")
+		return
+	}
+	defer rows.Close()
+
+	tags := make([]string, 0)
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			h.Logger.Printf("Error scanning tag row: %v", err)
+			models.WriteErrorResponse(w, http.StatusInternalServerError, "Internal server error")
+			return
+		}
+		tags = append(tags, name)
+	}
+	if err := rows.Err(); err != nil {
+		h.Logger.Printf("Database error suggesting tags: %v", err)
+		models.WriteErrorResponse(w, http.StatusInternalServerError, "Internal server error")
+		return
+	}
+
+	models.WriteJSONResponse(w, http.StatusOK, models.TagsResponse{Tags: tags})
+}
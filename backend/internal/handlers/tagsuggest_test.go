@@ -0,0 +1,40 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"slices"
+	"testing"
+
+	"github.com/realworld/backend/internal/models"
+)
+
+func TestSuggestTagsSkipsDraftAndUnusedTags(t *testing.T) {
+	h := newTestHandler(t)
+	h.TagSuggestMinPrefix = 2
+	h.TagSuggestMaxResults = 10
+	author := createTestUser(t, h, "author")
+
+	createTaggedArticle(t, h, author, "One", `["zebra-common","zebra-rare"]`)
+	createTaggedArticle(t, h, author, "Two", `["zebra-common"]`)
+	rec := serve("POST /api/articles", h.CreateArticle, "POST", "/api/articles",
+		`{"article":{"title":"Secret","description":"d","body":"b","published":false,"tagList":["zebra-secret"]}}`, author)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("creating draft: status %d: %s", rec.Code, rec.Body)
+	}
+	if _, err := h.DB.Exec("INSERT INTO tags (name) VALUES ('zebra-orphan')"); err != nil {
+		t.Fatalf("creating unused tag: %v", err)
+	}
+
+	rec = serve("GET /api/tags/suggest", h.SuggestTags, "GET", "/api/tags/suggest?q=ZEB", "", nil)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status %d: %s", rec.Code, rec.Body)
+	}
+	var resp models.TagsResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decoding response: %v", err)
+	}
+	if want := []string{"zebra-common", "zebra-rare"}; !slices.Equal(resp.Tags, want) {
+		t.Errorf("suggested %q, want %q", resp.Tags, want)
+	}
+}
@@ -0,0 +1,153 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/realworld/backend/internal/middleware"
+	"github.com/realworld/backend/internal/models"
+)
+
+// TransferArticle hands an article to another user. Only its author or an
+// admin may transfer it; the change is recorded in article_transfers.
+func (h *Handler) TransferArticle(w http.ResponseWriter, r *http.Request) {
+	authUser, ok := middleware.GetUserFromContext(r.Context())
+	if !ok {
+		models.WriteErrorResponse(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	var req models.TransferArticleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		models.WriteErrorResponse(w, http.StatusBadRequest, "Invalid JSON format")
+		return
+	}
+
+	username := strings.TrimSpace(req.Username)
+	if username == "" {
+		models.WriteErrorResponse(w, http.StatusUnprocessableEntity, models.ValidationErrors{
+			{Field: "username", Message: "is required"},
+		})
+		return
+	}
+
+	slug := r.PathValue("slug")
+	article, err := h.getArticleBySlugWithDrafts(slug, authUser.ID)
+	if err == sql.ErrNoRows {
+		models.WriteErrorResponse(w, http.StatusNotFound, "Article not found")
+		return
+	}
+	if err != nil {
+		h.Logger.Printf("Database error getting article: %v", err)
+		models.WriteErrorResponse(w, http.StatusInternalServerError, "Internal server error")
+		return
+	}
+
+	// Admins may transfer anyone's drafts; to everyone else other users'
+	// drafts don't exist
+	allowed := article.AuthorID == authUser.ID || h.IsAdmin(authUser)
+	if !article.Published && !allowed {
+		models.WriteErrorResponse(w, http.StatusNotFound, "Article not found")
+		return
+	}
+	if !allowed {
+		models.WriteErrorResponse(w, http.StatusForbidden, "You can only transfer your own articles")
+		return
+	}
+
+	var target models.User
+	err = h.DB.QueryRow(`
+		SELECT id, username, bio, image
+		FROM users WHERE username = ?
+	`, username).Scan(&target.ID, &target.Username, &target.Bio, &target.Image)
+	if err == sql.ErrNoRows {
+		models.WriteErrorResponse(w, http.StatusUnprocessableEntity, models.ValidationErrors{
+			{Field: "username", Message: "does not exist"},
+		})
+		return
+	}
+	if err != nil {
+		h.Logger.Printf("Database error getting transfer target: %v", err)
+		models.WriteErrorResponse(w, http.StatusInternalServerError, "Internal server error")
+		return
+	}
+
+	if target.ID == article.AuthorID {
+		models.WriteErrorResponse(w, http.StatusUnprocessableEntity, models.ValidationErrors{
+			{Field: "username", Message: "is already the author"},
+		})
+		return
+	}
+
+	// Nobody can have an article pushed onto them across a block
+	blocked, err := h.isBlockedBetween(article.AuthorID, target.ID)
+	if err != nil {
+		h.Logger.Printf("Database error checking blocks: %v", err)
+		models.WriteErrorResponse(w, http.StatusInternalServerError, "Internal server error")
+		return
+	}
+	if blocked {
+		models.WriteErrorResponse(w, http.StatusForbidden, "Cannot transfer articles to this user")
+		return
+	}
+
+	tx, err := h.DB.Begin()
+	if err != nil {
+		h.Logger.Printf("Database error starting transaction: %v", err)
+		models.WriteErrorResponse(w, http.StatusInternalServerError, "Internal server error")
+		return
+	}
+	defer tx.Rollback()
+
+	// The new author may already have a pinned article, so the pin stays
+	// behind with the old one
+	if _, err := tx.Exec("UPDATE articles SET author_id = ?, pinned = 0 WHERE id = ?", target.ID, article.ID); err != nil {
+		h.Logger.Printf("Database error transferring article: %v", err)
+		models.WriteErrorResponse(w, http.StatusInternalServerError, "Internal server error")
+		return
+	}
+
+	if _, err := tx.Exec(`
+		INSERT INTO article_transfers (article_id, from_user_id, to_user_id, transferred_by)
+		VALUES (?, ?, ?, ?)
+	`, article.ID, article.AuthorID, target.ID, authUser.ID); err != nil {
+		h.Logger.Printf("Database error recording article transfer: %v", err)
+		models.WriteErrorResponse(w, http.StatusInternalServerError, "Internal server error")
+		return
+	}
+
+	if err := tx.QueryRow("SELECT updated_at FROM articles WHERE id = ?", article.ID).Scan(&article.UpdatedAt); err != nil {
+		h.Logger.Printf("Database error getting transferred article: %v", err)
+		models.WriteErrorResponse(w, http.StatusInternalServerError, "Internal server error")
+		return
+	}
+
+	if err := tx.Commit(); err != nil {
+		h.Logger.Printf("Error committing transaction: %v", err)
+		models.WriteErrorResponse(w, http.StatusInternalServerError, "Internal server error")
+		return
+	}
+	h.invalidateArticleLists()
+
+	h.Logger.Printf("Article %q transferred from %s to %s by %s", article.Slug, article.Author.Username, target.Username, authUser.Username)
+
+	// Built from what was read rather than reloaded, since a transferred
+	// draft is no longer visible to the old author
+	var followCount int
+	h.DB.QueryRow(`
+		SELECT COUNT(*) FROM follows
+		WHERE follower_id = ? AND following_id = ?
+	`, authUser.ID, target.ID).Scan(&followCount)
+
+	article.AuthorID = target.ID
+	article.Pinned = false
+	article.Author = models.Profile{
+		Username:  target.Username,
+		Bio:       target.Bio,
+		Image:     models.DisplayImage(target.Image),
+		Following: followCount > 0,
+	}
+	models.WriteJSONResponse(w, http.StatusOK, models.ArticleResponse{Article: *article})
+}
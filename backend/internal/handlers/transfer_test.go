@@ -0,0 +1,60 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/realworld/backend/internal/middleware"
+	"github.com/realworld/backend/internal/models"
+)
+
+func TestTransferArticleDrafts(t *testing.T) {
+	h := newTestHandler(t)
+	author := createTestUser(t, h, "author")
+	admin := createTestUser(t, h, "moderator")
+	other := createTestUser(t, h, "other")
+	createTestUser(t, h, "heir")
+	h.Admins = map[string]bool{"moderator": true}
+
+	rec := serve("POST /api/articles", h.CreateArticle, "POST", "/api/articles",
+		`{"article":{"title":"Draft","description":"d","body":"b","published":false}}`, author)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("creating draft: status %d: %s", rec.Code, rec.Body)
+	}
+	var created models.ArticleResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &created); err != nil {
+		t.Fatalf("creating draft: %v", err)
+	}
+	draft := created.Article.Slug
+	published := createTestArticle(t, h, author, "Published")
+
+	tests := []struct {
+		name       string
+		slug       string
+		by         *middleware.User
+		wantStatus int
+	}{
+		{"other user's draft", draft, other, http.StatusNotFound},
+		{"other user's published article", published, other, http.StatusForbidden},
+		{"admin transfers a draft", draft, admin, http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := serve("POST /api/articles/{slug}/transfer", h.TransferArticle, "POST",
+				"/api/articles/"+tt.slug+"/transfer", `{"username":"heir"}`, tt.by)
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status %d, want %d: %s", rec.Code, tt.wantStatus, rec.Body)
+			}
+		})
+	}
+
+	var authorID int
+	if err := h.DB.QueryRow("SELECT author_id FROM articles WHERE slug = ?", draft).Scan(&authorID); err != nil {
+		t.Fatalf("reading draft: %v", err)
+	}
+	if authorID == author.ID {
+		t.Error("the admin's transfer left the draft with its old author")
+	}
+}
@@ -0,0 +1,98 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/realworld/backend/internal/models"
+)
+
+const (
+	defaultTrendingTags = 10
+	maxTrendingTags     = 50
+)
+
+// trendingCache holds the last computed ranking; it always keeps the top
+// maxTrendingTags so any requested limit can be served from it
+type trendingCache struct {
+	mu      sync.Mutex
+	tags    []models.TrendingTag
+	expires time.Time
+}
+
+// GetTrendingTags returns the tags whose articles gained the most favorites
+// in the last TrendingDays, skipping tags below TrendingMinFavorites
+func (h *Handler) GetTrendingTags(w http.ResponseWriter, r *http.Request) {
+	limit := defaultTrendingTags
+	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
+		l, err := strconv.Atoi(limitStr)
+		switch {
+		case err != nil:
+			models.WriteErrorResponse(w, http.StatusUnprocessableEntity, models.ValidationErrors{{Field: "limit", Message: "must be a number"}})
+			return
+		case l < 1:
+			models.WriteErrorResponse(w, http.StatusUnprocessableEntity, models.ValidationErrors{{Field: "limit", Message: "must be at least 1"}})
+			return
+		case l > maxTrendingTags:
+			limit = maxTrendingTags
+		default:
+			limit = l
+		}
+	}
+
+	h.trending.mu.Lock()
+	defer h.trending.mu.Unlock()
+
+	if now := time.Now(); !now.Before(h.trending.expires) {
+		tags, err := h.queryTrendingTags()
+		if err != nil {
+			h.Logger.Printf("Database error computing trending tags: %v", err)
+			models.WriteErrorResponse(w, http.StatusInternalServerError, "Internal server error")
+			return
+		}
+
+		h.trending.tags = tags
+		h.trending.expires = now.Add(h.TrendingCacheTTL)
+	}
+
+	tags := h.trending.tags
+	if len(tags) > limit {
+		tags = tags[:limit]
+	}
+
+	models.WriteJSONResponse(w, http.StatusOK, models.TrendingTagsResponse{Tags: tags})
+}
+
+// queryTrendingTags ranks tags by the favorites their articles received
+// within the window, most favorited first
+func (h *Handler) queryTrendingTags() ([]models.TrendingTag, error) {
+	rows, err := h.DB.Query(`
+		SELECT t.name, COUNT(*) AS recent_favorites
+		FROM favorites f
+		JOIN articles a ON a.id = f.article_id AND a.published = 1
+		JOIN article_tags at ON at.article_id = f.article_id
+		JOIN tags t ON t.id = at.tag_id
+		WHERE f.created_at > datetime('now', ?)
+		GROUP BY t.id
+		HAVING COUNT(*) >= ?
+		ORDER BY recent_favorites DESC, t.name
+		LIMIT ?
+	`, fmt.Sprintf("-%d days", h.TrendingDays), h.TrendingMinFavorites, maxTrendingTags)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tags := make([]models.TrendingTag, 0)
+	for rows.Next() {
+		var tag models.TrendingTag
+		if err := rows.Scan(&tag.Tag, &tag.Favorites); err != nil {
+			return nil, err
+		}
+		tags = append(tags, tag)
+	}
+	return tags, rows.Err()
+}
@@ -0,0 +1,230 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/realworld/backend/internal/models"
+	"github.com/realworld/backend/internal/utils"
+)
+
+const (
+	totpIssuer        = "RealWorld"
+	recoveryCodeCount = 10
+)
+
+// EnrollTwoFactor generates a new TOTP secret for the current user. 2FA stays
+// disabled until the user proves their authenticator works via ConfirmTwoFactor.
+func (h *Handler) EnrollTwoFactor(w http.ResponseWriter, r *http.Request) {
+	// A leaked API key must not be able to lock the owner out with 2FA
+	authUser, ok := requireSessionAuth(w, r, "Two-factor authentication can't be managed with an API key")
+	if !ok {
+		return
+	}
+
+	var email string
+	var enabled bool
+	err := h.DB.QueryRow("SELECT email, totp_enabled FROM users WHERE id = ?", authUser.ID).Scan(&email, &enabled)
+	if err != nil {
+		h.Logger.Printf("Database error getting user for 2FA enrollment: %v", err)
+		models.WriteErrorResponse(w, http.StatusInternalServerError, "Internal server error")
+		return
+	}
+
+	// Re-enrolling would silently replace a working secret
+	if enabled {
+		models.WriteErrorResponse(w, http.StatusUnprocessableEntity, "Two-factor authentication is already enabled")
+		return
+	}
+
+	secret, err := utils.GenerateTOTPSecret()
+	if err != nil {
+		h.Logger.Printf("TOTP secret generation error: %v", err)
+		models.WriteErrorResponse(w, http.StatusInternalServerError, "Internal server error")
+		return
+	}
+
+	encrypted, err := utils.EncryptSecret(secret, h.TwoFactorKey)
+	if err != nil {
+		h.Logger.Printf("TOTP secret encryption error: %v", err)
+		models.WriteErrorResponse(w, http.StatusInternalServerError, "Internal server error")
+		return
+	}
+
+	_, err = h.DB.Exec("UPDATE users SET totp_secret = ? WHERE id = ?", encrypted, authUser.ID)
+	if err != nil {
+		h.Logger.Printf("Database error storing TOTP secret: %v", err)
+		models.WriteErrorResponse(w, http.StatusInternalServerError, "Internal server error")
+		return
+	}
+
+	response := models.TwoFactorEnrollResponse{
+		Secret:     secret,
+		OtpauthURL: utils.TOTPProvisioningURI(totpIssuer, email, secret),
+	}
+
+	models.WriteJSONResponse(w, http.StatusOK, response)
+}
+
+// ConfirmTwoFactor enables 2FA once the user submits a valid code for the
+// enrolled secret, and returns a fresh set of single-use recovery codes
+func (h *Handler) ConfirmTwoFactor(w http.ResponseWriter, r *http.Request) {
+	// A leaked API key must not be able to lock the owner out with 2FA
+	authUser, ok := requireSessionAuth(w, r, "Two-factor authentication can't be managed with an API key")
+	if !ok {
+		return
+	}
+
+	var req models.TwoFactorConfirmRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		models.WriteErrorResponse(w, http.StatusBadRequest, "Invalid JSON format")
+		return
+	}
+
+	// Validate request
+	if validationErrors := req.Validate(); len(validationErrors) > 0 {
+		models.WriteErrorResponse(w, http.StatusUnprocessableEntity, validationErrors)
+		return
+	}
+
+	var encrypted string
+	var enabled bool
+	err := h.DB.QueryRow("SELECT totp_secret, totp_enabled FROM users WHERE id = ?", authUser.ID).Scan(&encrypted, &enabled)
+	if err != nil {
+		h.Logger.Printf("Database error getting TOTP secret: %v", err)
+		models.WriteErrorResponse(w, http.StatusInternalServerError, "Internal server error")
+		return
+	}
+
+	if enabled {
+		models.WriteErrorResponse(w, http.StatusUnprocessableEntity, "Two-factor authentication is already enabled")
+		return
+	}
+
+	if encrypted == "" {
+		models.WriteErrorResponse(w, http.StatusUnprocessableEntity, "Two-factor enrollment has not been started")
+		return
+	}
+
+	secret, err := utils.DecryptSecret(encrypted, h.TwoFactorKey)
+	if err != nil {
+		h.Logger.Printf("TOTP secret decryption error: %v", err)
+		models.WriteErrorResponse(w, http.StatusInternalServerError, "Internal server error")
+		return
+	}
+
+	if !utils.ValidateTOTP(secret, req.Code, time.Now()) {
+		var errors models.ValidationErrors
+		errors = append(errors, models.ValidationError{Field: "code", Message: "is invalid"})
+		models.WriteErrorResponse(w, http.StatusUnprocessableEntity, errors)
+		return
+	}
+
+	// Begin transaction
+	tx, err := h.DB.Begin()
+	if err != nil {
+		h.Logger.Printf("Database error starting transaction: %v", err)
+		models.WriteErrorResponse(w, http.StatusInternalServerError, "Internal server error")
+		return
+	}
+	defer tx.Rollback()
+
+	if _, err = tx.Exec("UPDATE users SET totp_enabled = 1 WHERE id = ?", authUser.ID); err != nil {
+		h.Logger.Printf("Database error enabling 2FA: %v", err)
+		models.WriteErrorResponse(w, http.StatusInternalServerError, "Internal server error")
+		return
+	}
+
+	// Replace any previous recovery codes
+	if _, err = tx.Exec("DELETE FROM recovery_codes WHERE user_id = ?", authUser.ID); err != nil {
+		h.Logger.Printf("Database error clearing recovery codes: %v", err)
+		models.WriteErrorResponse(w, http.StatusInternalServerError, "Internal server error")
+		return
+	}
+
+	codes := make([]string, 0, recoveryCodeCount)
+	for len(codes) < recoveryCodeCount {
+		code, err := utils.GenerateRecoveryCode()
+		if err != nil {
+			h.Logger.Printf("Recovery code generation error: %v", err)
+			models.WriteErrorResponse(w, http.StatusInternalServerError, "Internal server error")
+			return
+		}
+
+		_, err = tx.Exec(`
+			INSERT INTO recovery_codes (user_id, code_hash) 
+			VALUES (?, ?)
+		`, authUser.ID, utils.HashToken(code))
+		if err != nil {
+			h.Logger.Printf("Database error storing recovery code: %v", err)
+			models.WriteErrorResponse(w, http.StatusInternalServerError, "Internal server error")
+			return
+		}
+		codes = append(codes, code)
+	}
+
+	// Commit transaction
+	if err = tx.Commit(); err != nil {
+		h.Logger.Printf("Error committing transaction: %v", err)
+		models.WriteErrorResponse(w, http.StatusInternalServerError, "Internal server error")
+		return
+	}
+
+	response := models.TwoFactorConfirmResponse{
+		RecoveryCodes: codes,
+	}
+
+	models.WriteJSONResponse(w, http.StatusOK, response)
+}
+
+// checkSecondFactor verifies the TOTP or recovery code supplied at login for
+// users with 2FA enabled. It returns an empty message when login may proceed,
+// otherwise the reason to report with a 401.
+func (h *Handler) checkSecondFactor(userID int, totpCode, recoveryCode string) (string, error) {
+	if !h.TwoFactorEnabled {
+		return "", nil
+	}
+
+	var encrypted string
+	var enabled bool
+	err := h.DB.QueryRow("SELECT totp_secret, totp_enabled FROM users WHERE id = ?", userID).Scan(&encrypted, &enabled)
+	if err != nil {
+		return "", err
+	}
+
+	if !enabled {
+		return "", nil
+	}
+
+	if recoveryCode != "" {
+		// Consume the code atomically so it can only ever be used once
+		result, err := h.DB.Exec(`
+			UPDATE recovery_codes SET used_at = CURRENT_TIMESTAMP 
+			WHERE user_id = ? AND code_hash = ? AND used_at IS NULL
+		`, userID, utils.HashToken(recoveryCode))
+		if err != nil {
+			return "", err
+		}
+
+		if used, _ := result.RowsAffected(); used == 0 {
+			return "Invalid recovery code", nil
+		}
+		return "", nil
+	}
+
+	if totpCode == "" {
+		return "Two-factor authentication code required", nil
+	}
+
+	secret, err := utils.DecryptSecret(encrypted, h.TwoFactorKey)
+	if err != nil {
+		return "", err
+	}
+
+	if !utils.ValidateTOTP(secret, totpCode, time.Now()) {
+		return "Invalid two-factor authentication code", nil
+	}
+
+	return "", nil
+}
@@ -0,0 +1,41 @@
+package handlers
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestUpdateUserOptionalBio(t *testing.T) {
+	h := newTestHandler(t)
+	user := createTestUser(t, h, "alice")
+	if _, err := h.DB.Exec("UPDATE users SET bio = 'Original bio' WHERE id = ?", user.ID); err != nil {
+		t.Fatalf("setting bio: %v", err)
+	}
+
+	bio := func() string {
+		t.Helper()
+		var bio string
+		if err := h.DB.QueryRow("SELECT bio FROM users WHERE id = ?", user.ID).Scan(&bio); err != nil {
+			t.Fatalf("reading bio: %v", err)
+		}
+		return bio
+	}
+
+	update := func(body string) {
+		t.Helper()
+		rec := serve("PUT /api/user", h.UpdateUser, "PUT", "/api/user", body, user)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("PUT /api/user %s: status %d: %s", body, rec.Code, rec.Body)
+		}
+	}
+
+	update(`{"user":{"image":"https://example.com/a.png"}}`)
+	if got := bio(); got != "Original bio" {
+		t.Errorf("after omitting bio, bio = %q, want it unchanged", got)
+	}
+
+	update(`{"user":{"bio":null}}`)
+	if got := bio(); got != "" {
+		t.Errorf("after null bio, bio = %q, want it cleared", got)
+	}
+}
@@ -0,0 +1,24 @@
+package middleware
+
+import "net/http"
+
+// RequireAdmin returns a middleware, used after Auth, that only lets users
+// the isAdmin check accepts through
+func RequireAdmin(isAdmin func(*User) bool) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			user, ok := GetUserFromContext(r.Context())
+			if !ok {
+				writeError(w, http.StatusUnauthorized, "Unauthorized")
+				return
+			}
+
+			if !isAdmin(user) {
+				writeError(w, http.StatusForbidden, "Admin access required")
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
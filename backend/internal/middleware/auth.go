@@ -2,8 +2,10 @@ package middleware
 
 import (
 	"context"
+	"log"
 	"net/http"
 	"strings"
+	"time"
 
 	"github.com/realworld/backend/internal/utils"
 )
@@ -17,43 +19,104 @@ type User struct {
 	ID       int    `json:"id"`
 	Username string `json:"username"`
 	Email    string `json:"email"`
+	// APIKeyID is the key that authenticated the request; zero for a JWT
+	APIKeyID int `json:"-"`
+	// SessionID is the login session a JWT belongs to; empty for API keys
+	// and tokens issued before sessions were tracked
+	SessionID string `json:"-"`
 }
 
-// Auth returns a middleware that validates JWT tokens
-func Auth(secret string) func(http.Handler) http.Handler {
+// APIKeyResolver looks up the user an API key belongs to, returning a nil
+// user for unknown or revoked keys
+type APIKeyResolver interface {
+	ResolveAPIKey(key string) (*User, error)
+}
+
+// SessionChecker reports whether a login session may still be used, noting
+// that it was just seen. Sessions it doesn't know of are allowed.
+type SessionChecker interface {
+	SessionActive(sessionID string, userID int) (bool, error)
+}
+
+// AuthConfig configures Auth and OptionalAuth
+type AuthConfig struct {
+	// Secret verifies HS256 JWTs
+	Secret string
+	// Cutoffs rejects JWTs issued before a user's cutoff; nil skips the check
+	Cutoffs TokenCutoffs
+	// APIKeys enables the "Token <key>" scheme; nil rejects it
+	APIKeys APIKeyResolver
+	// Sessions rejects JWTs from revoked sessions; nil skips the check
+	Sessions SessionChecker
+	// FailureLog records each rejected credential for alerting; nil
+	// disables it
+	FailureLog *log.Logger
+}
+
+// LogFailure records why a request failed authentication along with the
+// client's address. Credentials themselves are never written.
+func (cfg AuthConfig) LogFailure(r *http.Request, reason string) {
+	if cfg.FailureLog == nil {
+		return
+	}
+	cfg.FailureLog.Printf("AUTH FAILURE: reason=%q ip=%s %s %s", reason, ClientIP(r), r.Method, r.URL.Path)
+}
+
+// Auth returns a middleware that requires either "Bearer <jwt>" or, for
+// scripts and integrations, "Token <api key>" authorization
+func Auth(cfg AuthConfig) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			// Get Authorization header
 			authHeader := r.Header.Get("Authorization")
 			if authHeader == "" {
+				cfg.LogFailure(r, "missing header")
 				writeError(w, http.StatusUnauthorized, "Authorization header required")
 				return
 			}
 
-			// Parse Bearer token
+			// Parse the scheme and credential
 			parts := strings.Split(authHeader, " ")
-			if len(parts) != 2 || parts[0] != "Bearer" {
+			if len(parts) != 2 || (parts[0] != "Bearer" && parts[0] != "Token") {
+				cfg.LogFailure(r, "malformed header")
 				writeError(w, http.StatusUnauthorized, "Invalid authorization header format")
 				return
 			}
 
-			tokenString := parts[1]
-			if tokenString == "" {
+			if parts[1] == "" {
+				cfg.LogFailure(r, "missing token")
 				writeError(w, http.StatusUnauthorized, "Token is required")
 				return
 			}
 
-			// Validate token
-			claims, err := utils.ValidateToken(tokenString, secret)
-			if err != nil {
-				writeError(w, http.StatusUnauthorized, "Invalid or expired token")
+			user, message, reason := authenticate(cfg, parts[0], parts[1])
+			if user == nil {
+				cfg.LogFailure(r, reason)
+				writeError(w, http.StatusUnauthorized, message)
 				return
 			}
 
-			// Create user object and add to context
-			user := &User{
-				ID:       claims.UserID,
-				Username: claims.Username,
+			ctx := context.WithValue(r.Context(), UserContextKey, user)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// OptionalAuth returns a middleware that attaches the user to the context when
+// valid credentials are supplied, but lets anonymous requests through unchanged
+func OptionalAuth(cfg AuthConfig) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			parts := strings.Split(r.Header.Get("Authorization"), " ")
+			if len(parts) != 2 || (parts[0] != "Bearer" && parts[0] != "Token") || parts[1] == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			user, _, _ := authenticate(cfg, parts[0], parts[1])
+			if user == nil {
+				next.ServeHTTP(w, r)
+				return
 			}
 
 			ctx := context.WithValue(r.Context(), UserContextKey, user)
@@ -62,6 +125,58 @@ func Auth(secret string) func(http.Handler) http.Handler {
 	}
 }
 
+// authenticate resolves a credential under the given scheme, returning the
+// user, or a nil user with the message for the client and the reason to log
+func authenticate(cfg AuthConfig, scheme, credential string) (*User, string, string) {
+	if scheme == "Token" {
+		if cfg.APIKeys == nil {
+			return nil, "Invalid authorization header format", "malformed header"
+		}
+		user, err := cfg.APIKeys.ResolveAPIKey(credential)
+		if err != nil || user == nil {
+			return nil, "Invalid API key", "invalid API key"
+		}
+		return user, "", ""
+	}
+
+	// Validate token
+	claims, err := utils.ValidateToken(credential, cfg.Secret)
+	if err != nil {
+		return nil, "Invalid or expired token", utils.TokenFailureReason(err)
+	}
+
+	// A lookup failure also rejects the token; it usually means the user no
+	// longer exists
+	if revoked, err := tokenRevoked(cfg.Cutoffs, claims.UserID, issuedAt(claims)); err != nil || revoked {
+		return nil, "Invalid or expired token", "revoked token"
+	}
+
+	// Tokens from before the sid claim identify their session by jti
+	sessionID := claims.SessionID
+	if sessionID == "" {
+		sessionID = claims.ID
+	}
+	if cfg.Sessions != nil && sessionID != "" {
+		if active, err := cfg.Sessions.SessionActive(sessionID, claims.UserID); err != nil || !active {
+			return nil, "Invalid or expired token", "revoked session"
+		}
+	}
+
+	return &User{
+		ID:        claims.UserID,
+		Username:  claims.Username,
+		SessionID: sessionID,
+	}, "", ""
+}
+
+// issuedAt returns a token's iat claim, or the zero time when it has none
+func issuedAt(claims *utils.Claims) time.Time {
+	if claims.IssuedAt == nil {
+		return time.Time{}
+	}
+	return claims.IssuedAt.Time
+}
+
 // GetUserFromContext extracts the authenticated user from the request context
 func GetUserFromContext(ctx context.Context) (*User, bool) {
 	user, ok := ctx.Value(UserContextKey).(*User)
@@ -0,0 +1,99 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func credentialedCORS() http.Handler {
+	opts := DefaultCORSOptions()
+	opts.AllowOrigins = []string{"https://app.example.com"}
+	opts.AllowCredentials = true
+	opts.AllowMethods = []string{"*"}
+	opts.AllowHeaders = []string{"*"}
+	opts.ExposeHeaders = []string{"*", "Authorization"}
+
+	return CORS(opts)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+}
+
+func TestCORSCredentialedPreflight(t *testing.T) {
+	req := httptest.NewRequest(http.MethodOptions, "/api/articles", nil)
+	req.Header.Set("Origin", "https://app.example.com")
+	req.Header.Set("Access-Control-Request-Method", "DELETE")
+	req.Header.Set("Access-Control-Request-Headers", "authorization, x-trace-id")
+
+	rec := httptest.NewRecorder()
+	credentialedCORS().ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status %d, want %d", rec.Code, http.StatusOK)
+	}
+
+	want := map[string]string{
+		"Access-Control-Allow-Origin":      "https://app.example.com",
+		"Access-Control-Allow-Credentials": "true",
+		"Access-Control-Allow-Methods":     "DELETE",
+		"Access-Control-Allow-Headers":     "authorization, x-trace-id",
+		"Access-Control-Expose-Headers":    "Authorization",
+	}
+	for name, value := range want {
+		if got := rec.Header().Get(name); got != value {
+			t.Errorf("%s = %q, want %q", name, got, value)
+		}
+	}
+
+	vary := rec.Header().Values("Vary")
+	for _, name := range []string{"Origin", "Access-Control-Request-Method", "Access-Control-Request-Headers"} {
+		if !slices.Contains(vary, name) {
+			t.Errorf("Vary = %q, missing %s", vary, name)
+		}
+	}
+
+	// Browsers reject a wildcard on credentialed responses
+	for name, values := range rec.Header() {
+		if strings.HasPrefix(name, "Access-Control-") && strings.Contains(strings.Join(values, ","), "*") {
+			t.Errorf("%s = %q contains a wildcard", name, values)
+		}
+	}
+}
+
+func TestCORSRejectsUnlistedOrigin(t *testing.T) {
+	req := httptest.NewRequest(http.MethodOptions, "/api/articles", nil)
+	req.Header.Set("Origin", "https://evil.example.com")
+	req.Header.Set("Access-Control-Request-Method", "DELETE")
+
+	rec := httptest.NewRecorder()
+	credentialedCORS().ServeHTTP(rec, req)
+
+	for _, name := range []string{"Access-Control-Allow-Origin", "Access-Control-Allow-Credentials", "Access-Control-Allow-Methods"} {
+		if got := rec.Header().Get(name); got != "" {
+			t.Errorf("%s = %q for an unlisted origin, want none", name, got)
+		}
+	}
+	if !slices.Contains(rec.Header().Values("Vary"), "Origin") {
+		t.Errorf("Vary = %q, missing Origin", rec.Header().Values("Vary"))
+	}
+}
+
+func TestCORSWithoutAllowlist(t *testing.T) {
+	opts := DefaultCORSOptions()
+	opts.AllowCredentials = true
+	handler := CORS(opts)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	req := httptest.NewRequest(http.MethodGet, "/api/tags", nil)
+	req.Header.Set("Origin", "https://anywhere.example.com")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if got := rec.Header().Get("Access-Control-Allow-Origin"); got != "*" {
+		t.Errorf("Access-Control-Allow-Origin = %q, want %q", got, "*")
+	}
+	if got := rec.Header().Get("Access-Control-Allow-Credentials"); got != "" {
+		t.Errorf("Access-Control-Allow-Credentials = %q with a wildcard origin, want none", got)
+	}
+}
@@ -0,0 +1,140 @@
+package middleware
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net/http"
+	"net/netip"
+	"sort"
+	"strings"
+)
+
+// GeoRanges maps IP ranges to ISO 3166 country codes
+type GeoRanges struct {
+	// ranges is sorted by start and never overlaps
+	ranges []geoRange
+}
+
+type geoRange struct {
+	start, end netip.Addr
+	country    string
+}
+
+// LoadGeoRanges reads a mapping with one "CIDR,COUNTRY" entry per line, such
+// as "203.0.113.0/24,AU". Blank lines and lines starting with # are skipped.
+func LoadGeoRanges(r io.Reader) (*GeoRanges, error) {
+	var ranges []geoRange
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+
+		cidr, country, ok := strings.Cut(text, ",")
+		if !ok {
+			return nil, fmt.Errorf("line %d: expected CIDR,COUNTRY", line)
+		}
+		prefix, err := netip.ParsePrefix(strings.TrimSpace(cidr))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: invalid CIDR %q", line, cidr)
+		}
+		country = strings.ToUpper(strings.TrimSpace(country))
+		if country == "" {
+			return nil, fmt.Errorf("line %d: missing country code", line)
+		}
+
+		prefix = prefix.Masked()
+		ranges = append(ranges, geoRange{start: prefix.Addr(), end: lastAddr(prefix), country: country})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	sort.Slice(ranges, func(i, j int) bool { return ranges[i].start.Less(ranges[j].start) })
+	for i := 1; i < len(ranges); i++ {
+		if ranges[i].start.Compare(ranges[i-1].end) <= 0 {
+			return nil, fmt.Errorf("ranges starting at %s and %s overlap", ranges[i-1].start, ranges[i].start)
+		}
+	}
+
+	return &GeoRanges{ranges: ranges}, nil
+}
+
+// Country returns the country code for addr, or "" when no range covers it
+func (g *GeoRanges) Country(addr netip.Addr) string {
+	addr = addr.Unmap()
+	i := sort.Search(len(g.ranges), func(i int) bool { return addr.Less(g.ranges[i].start) })
+	if i == 0 {
+		return ""
+	}
+	if r := g.ranges[i-1]; addr.Compare(r.end) <= 0 {
+		return r.country
+	}
+	return ""
+}
+
+// Len returns the number of ranges in the mapping
+func (g *GeoRanges) Len() int {
+	return len(g.ranges)
+}
+
+// lastAddr returns the highest address in a masked prefix
+func lastAddr(prefix netip.Prefix) netip.Addr {
+	b := prefix.Addr().AsSlice()
+	for i := prefix.Bits(); i < len(b)*8; i++ {
+		b[i/8] |= 1 << (7 - i%8)
+	}
+	addr, _ := netip.AddrFromSlice(b)
+	return addr
+}
+
+// GeoBlockOptions configures the GeoBlock middleware
+type GeoBlockOptions struct {
+	// Ranges maps client addresses to countries; nil disables blocking
+	Ranges *GeoRanges
+	// Allow, when non-empty, admits only these country codes
+	Allow map[string]bool
+	// Deny rejects these country codes
+	Deny map[string]bool
+	// BlockUnknown rejects clients whose address has no country in Ranges
+	BlockUnknown bool
+}
+
+// GeoBlock returns a middleware that answers 451 to clients from countries
+// the options don't admit. The client address comes from ClientIP, so
+// forwarding headers are only believed from TrustedProxies and a client
+// can't pick its country by sending X-Forwarded-For.
+func GeoBlock(opts GeoBlockOptions) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if opts.Ranges == nil {
+			return next
+		}
+
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var country string
+			if addr := parseClientAddr(ClientIP(r)); addr.IsValid() {
+				country = opts.Ranges.Country(addr)
+			}
+
+			if !opts.admits(country) {
+				writeError(w, http.StatusUnavailableForLegalReasons, "This service is not available in your region")
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// admits reports whether a client from country may proceed; "" is unknown
+func (opts GeoBlockOptions) admits(country string) bool {
+	if country == "" {
+		return !opts.BlockUnknown
+	}
+	if len(opts.Allow) > 0 && !opts.Allow[country] {
+		return false
+	}
+	return !opts.Deny[country]
+}
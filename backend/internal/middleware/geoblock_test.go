@@ -0,0 +1,70 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func geoBlockHandler(t *testing.T) http.Handler {
+	t.Helper()
+	ranges, err := LoadGeoRanges(strings.NewReader("203.0.113.0/24,AU\n198.51.100.0/24,XX\n"))
+	if err != nil {
+		t.Fatalf("LoadGeoRanges: %v", err)
+	}
+	opts := GeoBlockOptions{Ranges: ranges, Allow: map[string]bool{"AU": true}}
+	return GeoBlock(opts)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+}
+
+func setTrustedProxies(t *testing.T, value string) {
+	t.Helper()
+	saved := TrustedProxies
+	t.Cleanup(func() { TrustedProxies = saved })
+
+	TrustedProxies = nil
+	if value != "" {
+		proxies, err := ParseTrustedProxies(value)
+		if err != nil {
+			t.Fatalf("ParseTrustedProxies: %v", err)
+		}
+		TrustedProxies = proxies
+	}
+}
+
+func TestGeoBlock(t *testing.T) {
+	tests := []struct {
+		name       string
+		trusted    string
+		remoteAddr string
+		forwarded  string
+		wantStatus int
+	}{
+		{"allowed country", "", "203.0.113.5:1234", "", http.StatusNoContent},
+		{"blocked country", "", "198.51.100.5:1234", "", http.StatusUnavailableForLegalReasons},
+		{"spoofed header without trusted proxies", "", "198.51.100.5:1234", "203.0.113.5", http.StatusUnavailableForLegalReasons},
+		{"spoofed header from untrusted peer", "10.0.0.0/8", "198.51.100.5:1234", "203.0.113.5", http.StatusUnavailableForLegalReasons},
+		{"allowed client behind trusted proxy", "10.0.0.0/8", "10.0.0.2:1234", "203.0.113.5", http.StatusNoContent},
+		{"blocked client behind trusted proxy", "10.0.0.0/8", "10.0.0.2:1234", "198.51.100.5", http.StatusUnavailableForLegalReasons},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setTrustedProxies(t, tt.trusted)
+
+			req := httptest.NewRequest(http.MethodGet, "/api/tags", nil)
+			req.RemoteAddr = tt.remoteAddr
+			if tt.forwarded != "" {
+				req.Header.Set("X-Forwarded-For", tt.forwarded)
+			}
+			rec := httptest.NewRecorder()
+			geoBlockHandler(t).ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status %d, want %d", rec.Code, tt.wantStatus)
+			}
+		})
+	}
+}
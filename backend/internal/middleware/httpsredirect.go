@@ -0,0 +1,35 @@
+package middleware
+
+import "net/http"
+
+// HTTPSRedirect sends clients that reached a TLS-terminating proxy over plain
+// http to the https equivalent of the URL. The proxy reports the original
+// scheme in X-Forwarded-Proto, which is only believed from TrustedProxies, so
+// a client can't spoof it into a redirect loop; with none configured this is
+// a no-op. GET and HEAD get a 301; other methods get a 308 so the body isn't
+// dropped. /health is exempt so monitors can probe the plain listener.
+func HTTPSRedirect(enabled bool) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if !enabled {
+			return next
+		}
+
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == "/health" || !FromTrustedProxy(r) || ForwardedValue(r, "X-Forwarded-Proto") != "http" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			host := ForwardedValue(r, "X-Forwarded-Host")
+			if host == "" {
+				host = r.Host
+			}
+
+			status := http.StatusMovedPermanently
+			if r.Method != http.MethodGet && r.Method != http.MethodHead {
+				status = http.StatusPermanentRedirect
+			}
+			http.Redirect(w, r, "https://"+host+r.URL.RequestURI(), status)
+		})
+	}
+}
@@ -1,9 +1,22 @@
 package middleware
 
 import (
+	"bytes"
+	"fmt"
+	"io"
 	"log"
+	"math/rand/v2"
 	"net/http"
+	"net/netip"
+	"net/url"
+	"path"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"sync/atomic"
 	"time"
+	"unicode"
 )
 
 // Chain applies a series of middleware to a handler
@@ -14,19 +27,175 @@ func Chain(h http.Handler, middlewares ...func(http.Handler) http.Handler) http.
 	return h
 }
 
-// CORS middleware for handling Cross-Origin Resource Sharing
-func CORS() func(http.Handler) http.Handler {
+// CORSOptions configures the CORS middleware
+type CORSOptions struct {
+	// AllowOrigins are the origins allowed to read responses; empty allows
+	// any origin with "*"
+	AllowOrigins []string
+	// AllowCredentials lets browsers send cookies and credentials from
+	// AllowOrigins. It has no effect without AllowOrigins.
+	AllowCredentials bool
+	// AllowMethods are the request methods browsers may use
+	AllowMethods []string
+	// AllowHeaders are the request headers browsers may send
+	AllowHeaders []string
+	// ExposeHeaders are the response headers browsers let scripts read
+	ExposeHeaders []string
+	// MaxAge is how long, in seconds, browsers may cache a preflight response
+	MaxAge int
+}
+
+// DefaultCORSOptions returns the CORS settings used when none are configured
+func DefaultCORSOptions() CORSOptions {
+	return CORSOptions{
+		AllowMethods:  []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
+		AllowHeaders:  []string{"Content-Type", "Authorization", "X-Requested-With"},
+		ExposeHeaders: []string{"Authorization"},
+		MaxAge:        86400,
+	}
+}
+
+// ParseHeaderList parses a comma-separated list of HTTP header names,
+// rejecting anything that isn't a valid header name
+func ParseHeaderList(value string) ([]string, error) {
+	var headers []string
+	for _, name := range strings.Split(value, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		if !isHeaderName(name) {
+			return nil, fmt.Errorf("invalid header name %q", name)
+		}
+		headers = append(headers, name)
+	}
+	if len(headers) == 0 {
+		return nil, fmt.Errorf("no header names in %q", value)
+	}
+	return headers, nil
+}
+
+// ParseMethodList parses a comma-separated list of HTTP methods, or "*",
+// upper-casing each one
+func ParseMethodList(value string) ([]string, error) {
+	var methods []string
+	for _, method := range strings.Split(value, ",") {
+		method = strings.ToUpper(strings.TrimSpace(method))
+		if method == "" {
+			continue
+		}
+		if !isHeaderName(method) {
+			return nil, fmt.Errorf("invalid method %q", method)
+		}
+		methods = append(methods, method)
+	}
+	if len(methods) == 0 {
+		return nil, fmt.Errorf("no methods in %q", value)
+	}
+	return methods, nil
+}
+
+// ParseOriginList parses a comma-separated list of origins such as
+// https://example.com, rejecting anything with a path, query or fragment
+func ParseOriginList(value string) ([]string, error) {
+	var origins []string
+	for _, origin := range strings.Split(value, ",") {
+		origin = strings.TrimSpace(origin)
+		if origin == "" {
+			continue
+		}
+		u, err := url.Parse(origin)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" || u.User != nil ||
+			u.Path != "" || u.RawQuery != "" || u.Fragment != "" {
+			return nil, fmt.Errorf("invalid origin %q", origin)
+		}
+		origins = append(origins, strings.ToLower(origin))
+	}
+	if len(origins) == 0 {
+		return nil, fmt.Errorf("no origins in %q", value)
+	}
+	return origins, nil
+}
+
+// isHeaderName reports whether name is an RFC 7230 token
+func isHeaderName(name string) bool {
+	for _, c := range name {
+		if c > unicode.MaxASCII || !(unicode.IsLetter(c) || unicode.IsDigit(c) || strings.ContainsRune("!#$%&'*+-.^_`|~", c)) {
+			return false
+		}
+	}
+	return name != ""
+}
+
+// CORS middleware for handling Cross-Origin Resource Sharing. With
+// AllowOrigins, only those origins are echoed back; the rest get no CORS
+// headers. Browsers don't treat "*" as a wildcard on credentialed requests,
+// so with AllowCredentials a "*" method or header list echoes what the
+// preflight asks for instead, and "*" is dropped from ExposeHeaders.
+func CORS(opts CORSOptions) func(http.Handler) http.Handler {
+	allowMethods := strings.Join(opts.AllowMethods, ", ")
+	allowHeaders := strings.Join(opts.AllowHeaders, ", ")
+	exposeHeaders := strings.Join(opts.ExposeHeaders, ", ")
+	maxAge := strconv.Itoa(opts.MaxAge)
+
+	credentials := opts.AllowCredentials && len(opts.AllowOrigins) > 0
+	echoMethods := credentials && slices.Contains(opts.AllowMethods, "*")
+	echoHeaders := credentials && slices.Contains(opts.AllowHeaders, "*")
+	if credentials {
+		exposeHeaders = strings.Join(slices.DeleteFunc(slices.Clone(opts.ExposeHeaders), func(h string) bool {
+			return h == "*"
+		}), ", ")
+	}
+
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			preflight := r.Method == "OPTIONS"
+
 			// Set CORS headers
-			w.Header().Set("Access-Control-Allow-Origin", "*")
-			w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-			w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, X-Requested-With")
-			w.Header().Set("Access-Control-Expose-Headers", "Authorization")
-			w.Header().Set("Access-Control-Max-Age", "86400")
+			allowed := true
+			if len(opts.AllowOrigins) == 0 {
+				w.Header().Set("Access-Control-Allow-Origin", "*")
+			} else {
+				w.Header().Add("Vary", "Origin")
+				origin := r.Header.Get("Origin")
+				allowed = origin != "" && slices.Contains(opts.AllowOrigins, strings.ToLower(origin))
+				if allowed {
+					w.Header().Set("Access-Control-Allow-Origin", origin)
+				}
+			}
+
+			if allowed {
+				if credentials {
+					w.Header().Set("Access-Control-Allow-Credentials", "true")
+				}
+				if echoMethods {
+					if preflight {
+						w.Header().Add("Vary", "Access-Control-Request-Method")
+					}
+					if method := r.Header.Get("Access-Control-Request-Method"); method != "" {
+						w.Header().Set("Access-Control-Allow-Methods", method)
+					}
+				} else {
+					w.Header().Set("Access-Control-Allow-Methods", allowMethods)
+				}
+				if echoHeaders {
+					if preflight {
+						w.Header().Add("Vary", "Access-Control-Request-Headers")
+					}
+					if headers := r.Header.Get("Access-Control-Request-Headers"); headers != "" {
+						w.Header().Set("Access-Control-Allow-Headers", headers)
+					}
+				} else {
+					w.Header().Set("Access-Control-Allow-Headers", allowHeaders)
+				}
+				if exposeHeaders != "" {
+					w.Header().Set("Access-Control-Expose-Headers", exposeHeaders)
+				}
+				w.Header().Set("Access-Control-Max-Age", maxAge)
+			}
 
 			// Handle preflight requests
-			if r.Method == "OPTIONS" {
+			if preflight {
 				w.WriteHeader(http.StatusOK)
 				return
 			}
@@ -36,20 +205,53 @@ func CORS() func(http.Handler) http.Handler {
 	}
 }
 
+// LoggingOptions configures the Logging middleware
+type LoggingOptions struct {
+	// LogBodies logs request and response bodies for debugging. Off by default.
+	LogBodies bool
+	// MaxBodyBytes truncates each logged body to this many bytes
+	MaxBodyBytes int
+	// SampleRate is the fraction (0.0–1.0) of successful requests that are
+	// logged. Requests ending with a status of 400 or above are always logged.
+	SampleRate float64
+}
+
 // Logging middleware for request logging
-func Logging(logger *log.Logger) func(http.Handler) http.Handler {
+func Logging(logger *log.Logger, opts LoggingOptions) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			start := time.Now()
 
+			// Decide once up front, so the request line and its bodies are
+			// either all logged or all skipped
+			sampled := opts.SampleRate >= 1 || rand.Float64() < opts.SampleRate
+
 			// Create a custom ResponseWriter to capture status code
 			lw := &loggingResponseWriter{
 				ResponseWriter: w,
 				statusCode:     http.StatusOK,
 			}
 
+			// Capture bodies as the handler reads and writes them, so
+			// downstream code still sees the full, unmodified streams
+			var reqBody *cappedBuffer
+			if opts.LogBodies {
+				reqBody = &cappedBuffer{max: opts.MaxBodyBytes}
+				lw.body = &cappedBuffer{max: opts.MaxBodyBytes}
+				if r.Body != nil {
+					r.Body = struct {
+						io.Reader
+						io.Closer
+					}{io.TeeReader(r.Body, reqBody), r.Body}
+				}
+			}
+
 			next.ServeHTTP(lw, r)
 
+			if !sampled && lw.statusCode < http.StatusBadRequest {
+				return
+			}
+
 			duration := time.Since(start)
 			logger.Printf(
 				"%s %s %d %v %s %s",
@@ -60,6 +262,13 @@ func Logging(logger *log.Logger) func(http.Handler) http.Handler {
 				r.RemoteAddr,
 				r.UserAgent(),
 			)
+
+			if opts.LogBodies {
+				logger.Printf("%s %s request body: %s", r.Method, r.URL.Path,
+					describeBody(r.Header.Get("Content-Type"), reqBody))
+				logger.Printf("%s %s response body: %s", r.Method, r.URL.Path,
+					describeBody(lw.Header().Get("Content-Type"), lw.body))
+			}
 		})
 	}
 }
@@ -68,6 +277,7 @@ func Logging(logger *log.Logger) func(http.Handler) http.Handler {
 type loggingResponseWriter struct {
 	http.ResponseWriter
 	statusCode int
+	body       *cappedBuffer
 }
 
 func (lw *loggingResponseWriter) WriteHeader(code int) {
@@ -75,6 +285,58 @@ func (lw *loggingResponseWriter) WriteHeader(code int) {
 	lw.ResponseWriter.WriteHeader(code)
 }
 
+func (lw *loggingResponseWriter) Write(b []byte) (int, error) {
+	if lw.body != nil {
+		lw.body.Write(b)
+	}
+	return lw.ResponseWriter.Write(b)
+}
+
+// cappedBuffer keeps the first max bytes written to it and counts the rest
+type cappedBuffer struct {
+	buf   bytes.Buffer
+	max   int
+	total int
+}
+
+func (c *cappedBuffer) Write(p []byte) (int, error) {
+	c.total += len(p)
+	if room := c.max - c.buf.Len(); room > 0 {
+		if len(p) > room {
+			c.buf.Write(p[:room])
+		} else {
+			c.buf.Write(p)
+		}
+	}
+	return len(p), nil
+}
+
+// sensitiveFieldPattern matches JSON string fields whose values must never be logged
+var sensitiveFieldPattern = regexp.MustCompile(
+	`"(password|token|totpCode|recoveryCode|secret|otpauthUrl|key)"(\s*:\s*)"(?:[^"\\]|\\.)*"?`)
+
+// sensitiveArrayPattern matches JSON arrays of secrets such as recovery codes
+var sensitiveArrayPattern = regexp.MustCompile(`"(recoveryCodes)"(\s*:\s*)\[[^\]]*\]?`)
+
+// describeBody renders a captured body for the log, redacting secrets and
+// summarizing non-text payloads such as file uploads
+func describeBody(contentType string, body *cappedBuffer) string {
+	if body == nil || body.total == 0 {
+		return "(empty)"
+	}
+
+	if contentType != "" && !strings.Contains(contentType, "json") && !strings.HasPrefix(contentType, "text/") {
+		return fmt.Sprintf("(%d bytes of %s omitted)", body.total, contentType)
+	}
+
+	logged := sensitiveFieldPattern.ReplaceAllString(body.buf.String(), `"$1"$2"[REDACTED]"`)
+	logged = sensitiveArrayPattern.ReplaceAllString(logged, `"$1"$2["[REDACTED]"]`)
+	if body.total > body.buf.Len() {
+		logged += fmt.Sprintf("... (truncated, %d bytes total)", body.total)
+	}
+	return logged
+}
+
 // Recovery middleware for panic recovery
 func Recovery(logger *log.Logger) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
@@ -94,74 +356,267 @@ func Recovery(logger *log.Logger) func(http.Handler) http.Handler {
 	}
 }
 
-// RateLimit middleware for basic rate limiting
-func RateLimit() func(http.Handler) http.Handler {
-	// Simple in-memory rate limiter
-	// In production, you'd use Redis or a more sophisticated solution
-	clients := make(map[string][]time.Time)
-	const maxRequests = 100
-	const timeWindow = time.Minute
-
+// TrailingSlash canonicalizes paths ending in a slash so "/api/articles/"
+// reaches the same route as "/api/articles". GET and HEAD requests get a 301
+// to the canonical path; other methods are rewritten in place, since clients
+// commonly replay a redirected POST or PUT as a GET without its body. Place it
+// before MaxConcurrency so exact-path exemptions like /health still match.
+func TrailingSlash() func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			clientIP := getClientIP(r)
-			now := time.Now()
-
-			// Clean old entries
-			if requests, exists := clients[clientIP]; exists {
-				var validRequests []time.Time
-				for _, reqTime := range requests {
-					if now.Sub(reqTime) < timeWindow {
-						validRequests = append(validRequests, reqTime)
-					}
+			if r.URL.Path == "/" || !strings.HasSuffix(r.URL.Path, "/") {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			// Clean also collapses repeated slashes, so "//host/" can't
+			// become a redirect to another site
+			canonical := path.Clean(r.URL.Path)
+
+			if r.Method == http.MethodGet || r.Method == http.MethodHead {
+				target := canonical
+				if r.URL.RawQuery != "" {
+					target += "?" + r.URL.RawQuery
 				}
-				clients[clientIP] = validRequests
+				http.Redirect(w, r, target, http.StatusMovedPermanently)
+				return
 			}
 
-			// Check rate limit
-			if len(clients[clientIP]) >= maxRequests {
+			u := *r.URL
+			u.Path = canonical
+			u.RawPath = ""
+			r2 := r.Clone(r.Context())
+			r2.URL = &u
+			next.ServeHTTP(w, r2)
+		})
+	}
+}
+
+// MaxConcurrency caps the number of requests being served at once, answering
+// 503 when saturated. The health endpoint is exempt so monitors keep working.
+// Place it inside Recovery: the slot is released by a deferred call, so it is
+// freed even when a handler panics.
+func MaxConcurrency(n int) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if n <= 0 {
+			return next
+		}
+
+		slots := make(chan struct{}, n)
+
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == "/health" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			select {
+			case slots <- struct{}{}:
+				defer func() { <-slots }()
+			default:
+				w.Header().Set("Retry-After", "1")
 				w.Header().Set("Content-Type", "application/json; charset=utf-8")
-				w.WriteHeader(http.StatusTooManyRequests)
-				w.Write([]byte(`{"errors":{"body":["Rate limit exceeded"]}}`))
+				w.WriteHeader(http.StatusServiceUnavailable)
+				w.Write([]byte(`{"errors":{"body":["Server is busy, please retry"]}}`))
 				return
 			}
 
-			// Add current request
-			clients[clientIP] = append(clients[clientIP], now)
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// InFlight counts requests that are currently being served, so shutdown can
+// report how many it is waiting on
+type InFlight struct {
+	n atomic.Int64
+}
 
+// Middleware returns a middleware that tracks requests in the counter
+func (f *InFlight) Middleware() func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			f.n.Add(1)
+			defer f.n.Add(-1)
 			next.ServeHTTP(w, r)
 		})
 	}
 }
 
-// getClientIP extracts the client IP address from the request
-func getClientIP(r *http.Request) string {
-	// Check X-Forwarded-For header
-	xff := r.Header.Get("X-Forwarded-For")
-	if xff != "" {
-		// Take the first IP if multiple are present
-		if idx := len(xff); idx > 0 {
-			if commaIdx := 0; commaIdx < idx {
-				for i, char := range xff {
-					if char == ',' {
-						commaIdx = i
-						break
-					}
-				}
-				if commaIdx > 0 {
-					return xff[:commaIdx]
-				}
+// Count returns the number of requests in flight
+func (f *InFlight) Count() int64 {
+	return f.n.Load()
+}
+
+// TrustedProxies lists the peers whose X-Forwarded-For and X-Real-IP headers
+// are believed. When nil the headers are believed from anyone, which is only
+// safe behind a proxy that overwrites them.
+var TrustedProxies []netip.Prefix
+
+// ParseTrustedProxies parses a comma-separated list of CIDR ranges or single
+// IP addresses
+func ParseTrustedProxies(value string) ([]netip.Prefix, error) {
+	var prefixes []netip.Prefix
+	for _, entry := range strings.Split(value, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		if strings.Contains(entry, "/") {
+			prefix, err := netip.ParsePrefix(entry)
+			if err != nil {
+				return nil, fmt.Errorf("invalid CIDR %q", entry)
 			}
+			prefixes = append(prefixes, prefix.Masked())
+			continue
+		}
+		addr, err := netip.ParseAddr(entry)
+		if err != nil {
+			return nil, fmt.Errorf("invalid IP address %q", entry)
 		}
-		return xff
+		addr = addr.Unmap()
+		prefixes = append(prefixes, netip.PrefixFrom(addr, addr.BitLen()))
 	}
+	return prefixes, nil
+}
 
-	// Check X-Real-IP header
+// isTrustedProxy reports whether addr is in TrustedProxies
+func isTrustedProxy(addr netip.Addr) bool {
+	for _, prefix := range TrustedProxies {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// FromTrustedProxy reports whether the request arrived directly from one of
+// TrustedProxies. Unlike client IP detection, it is false when none are
+// configured, so X-Forwarded-Proto and X-Forwarded-Host are never believed
+// by default.
+func FromTrustedProxy(r *http.Request) bool {
+	if TrustedProxies == nil {
+		return false
+	}
+	peer := parseClientAddr(r.RemoteAddr)
+	return peer.IsValid() && isTrustedProxy(peer)
+}
+
+// ForwardedValue returns the first entry of a comma-separated forwarding
+// header, which the proxy nearest the client set, in lower case. Check
+// FromTrustedProxy before believing it.
+func ForwardedValue(r *http.Request, header string) string {
+	first, _, _ := strings.Cut(r.Header.Get(header), ",")
+	return strings.ToLower(strings.TrimSpace(first))
+}
+
+// parseClientAddr parses an address with or without a port, returning the
+// zero Addr when it isn't one
+func parseClientAddr(value string) netip.Addr {
+	value = strings.TrimSpace(value)
+	if addrPort, err := netip.ParseAddrPort(value); err == nil {
+		return addrPort.Addr().Unmap()
+	}
+	addr, err := netip.ParseAddr(strings.Trim(value, "[]"))
+	if err != nil {
+		return netip.Addr{}
+	}
+	return addr.Unmap()
+}
+
+// ClientIP returns the address the request came from, without a port. Unlike
+// getClientIP it never believes forwarding headers from untrusted peers, so it
+// is safe to record.
+func ClientIP(r *http.Request) string {
+	ip := trustedClientIP(r)
+	if addr := parseClientAddr(ip); addr.IsValid() {
+		return addr.String()
+	}
+	return ip
+}
+
+// Forwarding headers longer than maxForwardedLength are ignored rather than
+// parsed, and trustedClientIP walks at most maxForwardedHops hops, so an
+// oversized X-Forwarded-For can't slow every request or bloat the limiter
+const (
+	maxForwardedLength = 2048
+	maxForwardedHops   = 20
+)
+
+// forwardedFor returns the X-Forwarded-For hops, joined across repeated
+// headers, or nil when the header is absent or longer than maxForwardedLength
+func forwardedFor(r *http.Request) []string {
+	values := r.Header.Values("X-Forwarded-For")
+	if len(values) == 0 {
+		return nil
+	}
+	length := len(values) - 1
+	for _, value := range values {
+		length += len(value)
+	}
+	if length > maxForwardedLength {
+		return nil
+	}
+	return strings.Split(strings.Join(values, ","), ",")
+}
+
+// realIP returns the trimmed X-Real-IP header, or "" when it is absent or
+// longer than maxForwardedLength
+func realIP(r *http.Request) string {
 	xri := r.Header.Get("X-Real-IP")
-	if xri != "" {
+	if len(xri) > maxForwardedLength {
+		return ""
+	}
+	return strings.TrimSpace(xri)
+}
+
+// getClientIP extracts the client IP address from the request
+func getClientIP(r *http.Request) string {
+	if TrustedProxies != nil {
+		return trustedClientIP(r)
+	}
+
+	// Take the first X-Forwarded-For hop if multiple are present
+	if hops := forwardedFor(r); hops != nil {
+		return strings.TrimSpace(hops[0])
+	}
+
+	// Check X-Real-IP header
+	if xri := realIP(r); xri != "" {
 		return xri
 	}
 
 	// Use RemoteAddr as fallback
 	return r.RemoteAddr
-}
\ No newline at end of file
+}
+
+// trustedClientIP believes forwarding headers only from TrustedProxies. It
+// walks X-Forwarded-For from the right, skipping trusted hops, so a client
+// can't choose its address by sending the header itself.
+func trustedClientIP(r *http.Request) string {
+	peer := parseClientAddr(r.RemoteAddr)
+	if !peer.IsValid() || !isTrustedProxy(peer) {
+		return r.RemoteAddr
+	}
+
+	if hops := forwardedFor(r); hops != nil {
+		// Past maxForwardedHops the chain is treated as ending at the last
+		// hop examined
+		for i := len(hops) - 1; i >= 0; i-- {
+			hop := strings.TrimSpace(hops[i])
+			addr := parseClientAddr(hop)
+			if !addr.IsValid() {
+				return hop
+			}
+			if i == 0 || len(hops)-i >= maxForwardedHops || !isTrustedProxy(addr) {
+				return addr.String()
+			}
+		}
+	}
+
+	if xri := realIP(r); xri != "" {
+		return xri
+	}
+
+	return r.RemoteAddr
+}
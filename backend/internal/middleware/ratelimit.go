@@ -0,0 +1,173 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// RateLimiter decides whether another request identified by key may proceed.
+// When it may not, retryAfter reports how long the client should wait.
+type RateLimiter interface {
+	Allow(key string) (allowed bool, retryAfter time.Duration)
+}
+
+// RateLimit middleware rejects clients that exceed the limiter's allowance
+func RateLimit(limiter RateLimiter) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			// Check rate limit
+			allowed, retryAfter := limiter.Allow(getClientIP(r))
+			if !allowed {
+				seconds := int((retryAfter + time.Second - 1) / time.Second)
+				if seconds < 1 {
+					seconds = 1
+				}
+				w.Header().Set("Retry-After", strconv.Itoa(seconds))
+				w.Header().Set("Content-Type", "application/json; charset=utf-8")
+				w.WriteHeader(http.StatusTooManyRequests)
+				w.Write([]byte(`{"errors":{"body":["Rate limit exceeded"]}}`))
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// MemoryRateLimiter tracks request timestamps per key within a sliding window.
+// It is safe for concurrent use but only limits a single server instance.
+type MemoryRateLimiter struct {
+	mu          sync.Mutex
+	clients     map[string][]time.Time
+	maxRequests int
+	window      time.Duration
+	stop        chan struct{}
+	closeOnce   sync.Once
+}
+
+// NewMemoryRateLimiter creates a MemoryRateLimiter and starts a janitor that
+// evicts idle keys until Close is called
+func NewMemoryRateLimiter(maxRequests int, window time.Duration) *MemoryRateLimiter {
+	rl := &MemoryRateLimiter{
+		clients:     make(map[string][]time.Time),
+		maxRequests: maxRequests,
+		window:      window,
+		stop:        make(chan struct{}),
+	}
+
+	go func() {
+		ticker := time.NewTicker(window)
+		defer ticker.Stop()
+		for {
+			select {
+			case now := <-ticker.C:
+				rl.evictStale(now)
+			case <-rl.stop:
+				return
+			}
+		}
+	}()
+
+	return rl
+}
+
+// Close stops the janitor goroutine. It is safe to call more than once.
+func (rl *MemoryRateLimiter) Close() {
+	rl.closeOnce.Do(func() { close(rl.stop) })
+}
+
+// Allow records a request for the key and reports whether it is within the limit
+func (rl *MemoryRateLimiter) Allow(key string) (bool, time.Duration) {
+	return rl.allow(key, time.Now())
+}
+
+func (rl *MemoryRateLimiter) allow(key string, now time.Time) (bool, time.Duration) {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	// Clean old entries
+	validRequests := rl.clients[key][:0]
+	for _, reqTime := range rl.clients[key] {
+		if now.Sub(reqTime) < rl.window {
+			validRequests = append(validRequests, reqTime)
+		}
+	}
+
+	if len(validRequests) >= rl.maxRequests {
+		rl.clients[key] = validRequests
+		// The oldest request leaving the window frees the next slot
+		return false, validRequests[0].Add(rl.window).Sub(now)
+	}
+
+	// Add current request
+	rl.clients[key] = append(validRequests, now)
+	return true, 0
+}
+
+// evictStale removes keys with no requests inside the window so the map stays bounded
+func (rl *MemoryRateLimiter) evictStale(now time.Time) {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	for key, requests := range rl.clients {
+		if len(requests) == 0 || now.Sub(requests[len(requests)-1]) >= rl.window {
+			delete(rl.clients, key)
+		}
+	}
+}
+
+// redisRateLimitScript counts requests in a fixed window, starting the window's
+// expiry on the first request, and returns the count with the remaining TTL
+var redisRateLimitScript = redis.NewScript(`
+local count = redis.call("INCR", KEYS[1])
+if count == 1 then
+	redis.call("PEXPIRE", KEYS[1], ARGV[1])
+end
+return {count, redis.call("PTTL", KEYS[1])}
+`)
+
+// RedisRateLimiter counts requests per key in Redis so that limits are shared
+// across server instances
+type RedisRateLimiter struct {
+	client      *redis.Client
+	name        string
+	maxRequests int
+	window      time.Duration
+	timeout     time.Duration
+}
+
+// NewRedisRateLimiter creates a RedisRateLimiter using the given client. The
+// name keeps counters separate when several limiters share one Redis.
+func NewRedisRateLimiter(client *redis.Client, name string, maxRequests int, window time.Duration) *RedisRateLimiter {
+	return &RedisRateLimiter{
+		client:      client,
+		name:        name,
+		maxRequests: maxRequests,
+		window:      window,
+		timeout:     100 * time.Millisecond,
+	}
+}
+
+// Allow records a request for the key and reports whether it is within the limit.
+// Requests are allowed when Redis is unavailable so an outage doesn't take the API down.
+func (rl *RedisRateLimiter) Allow(key string) (bool, time.Duration) {
+	ctx, cancel := context.WithTimeout(context.Background(), rl.timeout)
+	defer cancel()
+
+	result, err := redisRateLimitScript.Run(ctx, rl.client,
+		[]string{"ratelimit:" + rl.name + ":" + key}, rl.window.Milliseconds()).Int64Slice()
+	if err != nil || len(result) != 2 {
+		return true, 0
+	}
+
+	if result[0] > int64(rl.maxRequests) {
+		return false, time.Duration(result[1]) * time.Millisecond
+	}
+
+	return true, 0
+}
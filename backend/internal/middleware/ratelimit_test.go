@@ -0,0 +1,123 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestMemoryRateLimiterConcurrent(t *testing.T) {
+	const (
+		maxRequests = 50
+		keys        = 4
+		workers     = 16
+		perWorker   = 100
+	)
+
+	// A short window keeps the janitor running alongside Allow
+	rl := NewMemoryRateLimiter(maxRequests, 10*time.Millisecond)
+	defer rl.Close()
+
+	// A long-window limiter checks the count is exact under contention
+	exact := NewMemoryRateLimiter(maxRequests, time.Hour)
+	defer exact.Close()
+
+	var allowed [keys]atomic.Int64
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < perWorker; j++ {
+				key := strconv.Itoa((i + j) % keys)
+				rl.Allow(key)
+				if ok, _ := exact.Allow(key); ok {
+					allowed[(i+j)%keys].Add(1)
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	for key := range allowed {
+		if got := allowed[key].Load(); got != maxRequests {
+			t.Errorf("key %d: allowed %d requests, want %d", key, got, maxRequests)
+		}
+	}
+}
+
+func TestMemoryRateLimiterRetryAfter(t *testing.T) {
+	rl := NewMemoryRateLimiter(2, time.Minute)
+	defer rl.Close()
+
+	now := time.Now()
+	rl.allow("k", now)
+	rl.allow("k", now.Add(10*time.Second))
+
+	ok, retryAfter := rl.allow("k", now.Add(20*time.Second))
+	if ok {
+		t.Fatal("third request in window was allowed")
+	}
+	if retryAfter != 40*time.Second {
+		t.Errorf("retryAfter = %s, want 40s", retryAfter)
+	}
+
+	if ok, _ := rl.allow("k", now.Add(time.Minute)); !ok {
+		t.Error("request after the oldest left the window was refused")
+	}
+}
+
+func TestMemoryRateLimiterCloseStopsJanitor(t *testing.T) {
+	before := runtime.NumGoroutine()
+
+	limiters := make([]*MemoryRateLimiter, 20)
+	for i := range limiters {
+		limiters[i] = NewMemoryRateLimiter(1, time.Millisecond)
+	}
+	for _, rl := range limiters {
+		rl.Close()
+		rl.Close()
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for runtime.NumGoroutine() > before {
+		if time.Now().After(deadline) {
+			t.Fatalf("%d goroutines still running after Close, had %d before", runtime.NumGoroutine(), before)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestRateLimitMiddleware(t *testing.T) {
+	rl := NewMemoryRateLimiter(1, time.Minute)
+	defer rl.Close()
+
+	handler := RateLimit(rl)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	request := func() *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/api/tags", nil)
+		req.RemoteAddr = "192.0.2.1:1234"
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	if rec := request(); rec.Code != http.StatusNoContent {
+		t.Fatalf("first request: status %d, want %d", rec.Code, http.StatusNoContent)
+	}
+
+	rec := request()
+	if rec.Code != http.StatusTooManyRequests {
+		t.Fatalf("second request: status %d, want %d", rec.Code, http.StatusTooManyRequests)
+	}
+	if got := rec.Header().Get("Retry-After"); got != "60" {
+		t.Errorf("Retry-After = %q, want %q", got, "60")
+	}
+}
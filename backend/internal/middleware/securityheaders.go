@@ -0,0 +1,98 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+)
+
+// SecurityHeadersOptions configures SecurityHeaders; a zero value field omits
+// its header
+type SecurityHeadersOptions struct {
+	// NoSniff sends X-Content-Type-Options: nosniff
+	NoSniff bool
+	// FrameDeny sends X-Frame-Options: DENY
+	FrameDeny bool
+	// ReferrerPolicy is the Referrer-Policy value
+	ReferrerPolicy string
+	// HSTSMaxAge is the Strict-Transport-Security max-age in seconds
+	HSTSMaxAge int
+	// HSTSIncludeSubdomains extends Strict-Transport-Security to subdomains
+	HSTSIncludeSubdomains bool
+}
+
+// DefaultSecurityHeadersOptions returns the headers sent when none are
+// configured. HSTS is off, as it can't be taken back from browsers that saw it.
+func DefaultSecurityHeadersOptions() SecurityHeadersOptions {
+	return SecurityHeadersOptions{
+		NoSniff:        true,
+		FrameDeny:      true,
+		ReferrerPolicy: "no-referrer",
+	}
+}
+
+// referrerPolicies are the Referrer-Policy values browsers understand
+var referrerPolicies = map[string]bool{
+	"no-referrer":                     true,
+	"no-referrer-when-downgrade":      true,
+	"origin":                          true,
+	"origin-when-cross-origin":        true,
+	"same-origin":                     true,
+	"strict-origin":                   true,
+	"strict-origin-when-cross-origin": true,
+	"unsafe-url":                      true,
+}
+
+// Validate reports a Referrer-Policy browsers wouldn't understand or a
+// negative HSTS max-age
+func (o SecurityHeadersOptions) Validate() error {
+	if o.ReferrerPolicy != "" && !referrerPolicies[o.ReferrerPolicy] {
+		return fmt.Errorf("unknown referrer policy %q", o.ReferrerPolicy)
+	}
+	if o.HSTSMaxAge < 0 {
+		return fmt.Errorf("HSTS max-age must not be negative, got %d", o.HSTSMaxAge)
+	}
+	return nil
+}
+
+// SecurityHeaders adds browser hardening headers to every response.
+// Strict-Transport-Security is only sent on https requests, which includes
+// those a trusted proxy reports as https, since browsers ignore it over http.
+func SecurityHeaders(opts SecurityHeadersOptions) func(http.Handler) http.Handler {
+	var hsts string
+	if opts.HSTSMaxAge > 0 {
+		hsts = "max-age=" + strconv.Itoa(opts.HSTSMaxAge)
+		if opts.HSTSIncludeSubdomains {
+			hsts += "; includeSubDomains"
+		}
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			header := w.Header()
+			if opts.NoSniff {
+				header.Set("X-Content-Type-Options", "nosniff")
+			}
+			if opts.FrameDeny {
+				header.Set("X-Frame-Options", "DENY")
+			}
+			if opts.ReferrerPolicy != "" {
+				header.Set("Referrer-Policy", opts.ReferrerPolicy)
+			}
+			if hsts != "" && isHTTPS(r) {
+				header.Set("Strict-Transport-Security", hsts)
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// isHTTPS reports whether the client connected over https, either directly or
+// to a trusted proxy in front of us
+func isHTTPS(r *http.Request) bool {
+	if r.TLS != nil {
+		return true
+	}
+	return FromTrustedProxy(r) && ForwardedValue(r, "X-Forwarded-Proto") == "https"
+}
@@ -0,0 +1,93 @@
+package middleware
+
+import (
+	"sync"
+	"time"
+)
+
+// TokenCutoffs reports the time before which a user's tokens are no longer
+// accepted. A zero time means every unexpired token is accepted.
+type TokenCutoffs interface {
+	ValidAfter(userID int) (time.Time, error)
+}
+
+// TokenCutoffCache caches per-user token cutoffs so Auth doesn't query the
+// database on every request. A cutoff changed elsewhere takes up to the TTL
+// to be noticed; Invalidate applies a local change immediately.
+type TokenCutoffCache struct {
+	lookup func(userID int) (time.Time, error)
+	ttl    time.Duration
+
+	mu      sync.Mutex
+	entries map[int]tokenCutoffEntry
+}
+
+type tokenCutoffEntry struct {
+	validAfter time.Time
+	expires    time.Time
+}
+
+// NewTokenCutoffCache creates a cache that loads cutoffs with lookup and keeps
+// them for ttl
+func NewTokenCutoffCache(lookup func(userID int) (time.Time, error), ttl time.Duration) *TokenCutoffCache {
+	return &TokenCutoffCache{
+		lookup:  lookup,
+		ttl:     ttl,
+		entries: make(map[int]tokenCutoffEntry),
+	}
+}
+
+// ValidAfter returns the user's cutoff, loading it when missing or stale
+func (c *TokenCutoffCache) ValidAfter(userID int) (time.Time, error) {
+	now := time.Now()
+
+	c.mu.Lock()
+	entry, ok := c.entries[userID]
+	c.mu.Unlock()
+	if ok && now.Before(entry.expires) {
+		return entry.validAfter, nil
+	}
+
+	validAfter, err := c.lookup(userID)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	c.mu.Lock()
+	// Drop expired entries as the map fills so departed users don't pile up
+	if len(c.entries) >= maxTokenCutoffEntries {
+		for id, e := range c.entries {
+			if !now.Before(e.expires) {
+				delete(c.entries, id)
+			}
+		}
+	}
+	c.entries[userID] = tokenCutoffEntry{validAfter: validAfter, expires: now.Add(c.ttl)}
+	c.mu.Unlock()
+
+	return validAfter, nil
+}
+
+// Invalidate forgets a user's cached cutoff, so a change is enforced by the
+// next request
+func (c *TokenCutoffCache) Invalidate(userID int) {
+	c.mu.Lock()
+	delete(c.entries, userID)
+	c.mu.Unlock()
+}
+
+// maxTokenCutoffEntries is the cache size at which expired entries are swept
+const maxTokenCutoffEntries = 10000
+
+// tokenRevoked reports whether a token issued at issuedAt predates the user's cutoff
+func tokenRevoked(cutoffs TokenCutoffs, userID int, issuedAt time.Time) (bool, error) {
+	if cutoffs == nil {
+		return false, nil
+	}
+
+	validAfter, err := cutoffs.ValidAfter(userID)
+	if err != nil {
+		return false, err
+	}
+	return issuedAt.Before(validAfter), nil
+}
@@ -0,0 +1,110 @@
+package middleware
+
+import (
+	"context"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// APIVersionContextKey holds the API version negotiated for a request
+const APIVersionContextKey = contextKey("apiVersion")
+
+// Supported response shape versions. Handlers branch on APIVersionFromContext
+// when a shape changes, keeping older versions stable for pinned clients.
+const (
+	APIVersion1       = 1
+	LatestAPIVersion  = APIVersion1
+	vendorMediaPrefix = "application/vnd.realworld.v"
+	vendorMediaSuffix = "+json"
+)
+
+// APIVersion negotiates the response version from the Accept header. Clients
+// pin a version with "application/vnd.realworld.v1+json" and get responses
+// labelled with that media type; anything else gets the latest version as
+// plain JSON. A pinned version the server doesn't support is a 406.
+func APIVersion() func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			version, pinned, ok := negotiateVersion(r.Header.Get("Accept"))
+			if !ok {
+				writeError(w, http.StatusNotAcceptable, "Unsupported API version")
+				return
+			}
+
+			w.Header().Add("Vary", "Accept")
+			if pinned {
+				w = &versionedResponseWriter{
+					ResponseWriter: w,
+					mediaType:      vendorMediaPrefix + strconv.Itoa(version) + vendorMediaSuffix,
+				}
+			}
+
+			ctx := context.WithValue(r.Context(), APIVersionContextKey, version)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// APIVersionFromContext returns the negotiated API version, defaulting to the latest
+func APIVersionFromContext(ctx context.Context) int {
+	if version, ok := ctx.Value(APIVersionContextKey).(int); ok {
+		return version
+	}
+	return LatestAPIVersion
+}
+
+// negotiateVersion picks the first supported vendor media type in the Accept
+// header. Without one, it falls back to the latest version unless the header
+// only lists unsupported vendor versions.
+func negotiateVersion(accept string) (version int, pinned bool, ok bool) {
+	sawVendor, sawOther := false, accept == ""
+
+	for _, part := range strings.Split(accept, ",") {
+		mediaType, _, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+
+		if !strings.HasPrefix(mediaType, vendorMediaPrefix) || !strings.HasSuffix(mediaType, vendorMediaSuffix) {
+			sawOther = true
+			continue
+		}
+
+		sawVendor = true
+		v, err := strconv.Atoi(strings.TrimSuffix(strings.TrimPrefix(mediaType, vendorMediaPrefix), vendorMediaSuffix))
+		if err == nil && v >= APIVersion1 && v <= LatestAPIVersion {
+			return v, true, true
+		}
+	}
+
+	if sawVendor && !sawOther {
+		return 0, false, false
+	}
+	return LatestAPIVersion, false, true
+}
+
+// versionedResponseWriter relabels JSON responses with the pinned vendor media type
+type versionedResponseWriter struct {
+	http.ResponseWriter
+	mediaType   string
+	wroteHeader bool
+}
+
+func (vw *versionedResponseWriter) WriteHeader(code int) {
+	if !vw.wroteHeader {
+		vw.wroteHeader = true
+		if strings.HasPrefix(vw.Header().Get("Content-Type"), "application/json") {
+			vw.Header().Set("Content-Type", vw.mediaType+"; charset=utf-8")
+		}
+	}
+	vw.ResponseWriter.WriteHeader(code)
+}
+
+func (vw *versionedResponseWriter) Write(b []byte) (int, error) {
+	if !vw.wroteHeader {
+		vw.WriteHeader(http.StatusOK)
+	}
+	return vw.ResponseWriter.Write(b)
+}
@@ -0,0 +1,43 @@
+package models
+
+// MaxAPIKeyNameLength caps the label given to an API key
+const MaxAPIKeyNameLength = 100
+
+// APIKey represents a user's API key. Key is only set in the response that
+// creates it; afterwards only Prefix identifies it.
+type APIKey struct {
+	ID        int       `json:"id"`
+	Name      string    `json:"name"`
+	Prefix    string    `json:"prefix"`
+	Key       string    `json:"key,omitempty"`
+	CreatedAt Timestamp `json:"createdAt"`
+}
+
+// CreateAPIKeyRequest represents the request payload for creating an API key
+type CreateAPIKeyRequest struct {
+	APIKey struct {
+		Name string `json:"name"`
+	} `json:"apiKey"`
+}
+
+// APIKeyResponse represents the response format for a single API key
+type APIKeyResponse struct {
+	APIKey APIKey `json:"apiKey"`
+}
+
+// APIKeysResponse represents the response format for a user's API keys
+type APIKeysResponse struct {
+	APIKeys []APIKey `json:"apiKeys"`
+}
+
+// Validate validates a CreateAPIKeyRequest
+func (r *CreateAPIKeyRequest) Validate() ValidationErrors {
+	var errors ValidationErrors
+
+	if len(r.APIKey.Name) > MaxAPIKeyNameLength {
+		errors = append(errors, ValidationError{"name", "must be at most 100 characters"})
+	}
+	errors = append(errors, validateText("name", r.APIKey.Name)...)
+
+	return errors
+}
@@ -2,23 +2,65 @@ package models
 
 import (
 	"errors"
-	"time"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/realworld/backend/internal/utils"
 )
 
 // Article represents an article in the system
 type Article struct {
-	ID             int       `json:"id" db:"id"`
-	Slug           string    `json:"slug" db:"slug"`
-	Title          string    `json:"title" db:"title"`
-	Description    string    `json:"description" db:"description"`
-	Body           string    `json:"body" db:"body"`
-	AuthorID       int       `json:"-" db:"author_id"`
-	CreatedAt      time.Time `json:"createdAt" db:"created_at"`
-	UpdatedAt      time.Time `json:"updatedAt" db:"updated_at"`
-	Favorited      bool      `json:"favorited"`
-	FavoritesCount int       `json:"favoritesCount"`
-	TagList        []string  `json:"tagList"`
-	Author         Profile   `json:"author"`
+	ID              int       `json:"id" db:"id"`
+	Slug            string    `json:"slug" db:"slug"`
+	Title           string    `json:"title" db:"title"`
+	Description     string    `json:"description" db:"description"`
+	Body            string    `json:"body" db:"body"`
+	AuthorID        int       `json:"-" db:"author_id"`
+	CreatedAt       Timestamp `json:"createdAt" db:"created_at"`
+	UpdatedAt       Timestamp `json:"updatedAt" db:"updated_at"`
+	Favorited       bool      `json:"favorited"`
+	FavoritesCount  int       `json:"favoritesCount"`
+	TagList         []string  `json:"tagList"`
+	Author          Profile   `json:"author"`
+	CoverImage      *string   `json:"coverImage" db:"cover_image"`
+	Version         int       `json:"version" db:"version"`
+	CommentsEnabled bool      `json:"commentsEnabled" db:"comments_enabled"`
+	// Published is false for a draft, which only its author can see
+	Published bool `json:"published" db:"published"`
+	// Pinned marks the article its author keeps atop their profile listing
+	Pinned bool `json:"pinned,omitempty" db:"pinned"`
+	// Source marks why an article is in a discover feed: "following" or "recommended"
+	Source string `json:"source,omitempty"`
+	// Excerpt is a plain-text preview of the body, only set in list responses
+	Excerpt string `json:"excerpt,omitempty"`
+}
+
+// Feed article sources reported in discover mode
+const (
+	ArticleSourceFollowing   = "following"
+	ArticleSourceRecommended = "recommended"
+)
+
+// Article list orders accepted by ?sort= and DEFAULT_ARTICLE_SORT
+const (
+	ArticleSortNewest    = "newest"
+	ArticleSortOldest    = "oldest"
+	ArticleSortFavorites = "favorites"
+)
+
+// ArticleSorts lists the supported article list orders
+var ArticleSorts = []string{ArticleSortNewest, ArticleSortOldest, ArticleSortFavorites}
+
+// ValidArticleSort reports whether sort names a supported article list order
+func ValidArticleSort(sort string) bool {
+	for _, s := range ArticleSorts {
+		if s == sort {
+			return true
+		}
+	}
+	return false
 }
 
 // CreateArticleRequest represents the request payload for creating an article
@@ -28,19 +70,60 @@ type CreateArticleRequest struct {
 		Description string   `json:"description"`
 		Body        string   `json:"body"`
 		TagList     []string `json:"tagList"`
+		CoverImage  string   `json:"coverImage"`
+		// CommentsEnabled defaults to true when omitted
+		CommentsEnabled *bool `json:"commentsEnabled"`
+		// Published defaults to true when omitted; false saves a draft
+		Published *bool `json:"published"`
+		// Slug is generated from the title when omitted
+		Slug string `json:"slug"`
 	} `json:"article"`
 }
 
 // UpdateArticleRequest represents the request payload for updating an article
 type UpdateArticleRequest struct {
 	Article struct {
-		Title       string   `json:"title,omitempty"`
-		Description string   `json:"description,omitempty"`
-		Body        string   `json:"body,omitempty"`
-		TagList     []string `json:"tagList,omitempty"`
+		Title           string   `json:"title,omitempty"`
+		Description     string   `json:"description,omitempty"`
+		Body            string   `json:"body,omitempty"`
+		TagList         []string `json:"tagList,omitempty"`
+		CoverImage      string   `json:"coverImage,omitempty"`
+		CommentsEnabled *bool    `json:"commentsEnabled,omitempty"`
+		Published       *bool    `json:"published,omitempty"`
+		Slug            string   `json:"slug,omitempty"`
+		// Version is the version being edited; a stale one is rejected
+		Version *int `json:"version,omitempty"`
+	} `json:"article"`
+}
+
+// PatchArticleRequest represents the request payload for partially updating an article.
+// Nil fields are left unchanged, while an explicit empty string clears the field.
+type PatchArticleRequest struct {
+	Article struct {
+		Title           *string  `json:"title"`
+		Description     *string  `json:"description"`
+		Body            *string  `json:"body"`
+		TagList         []string `json:"tagList"`
+		CoverImage      *string  `json:"coverImage"`
+		CommentsEnabled *bool    `json:"commentsEnabled"`
+		Published       *bool    `json:"published"`
+		Slug            *string  `json:"slug"`
+		Version         *int     `json:"version"`
 	} `json:"article"`
 }
 
+// SetFavoriteRequest represents the request payload for setting an article's
+// favorited state
+type SetFavoriteRequest struct {
+	Favorited *bool `json:"favorited"`
+}
+
+// TransferArticleRequest represents the request payload for handing an
+// article to another author
+type TransferArticleRequest struct {
+	Username string `json:"username"`
+}
+
 // ArticleResponse represents the response format for a single article
 type ArticleResponse struct {
 	Article Article `json:"article"`
@@ -50,17 +133,199 @@ type ArticleResponse struct {
 type ArticlesResponse struct {
 	Articles      []Article `json:"articles"`
 	ArticlesCount int       `json:"articlesCount"`
+	// Suggestions lists popular articles when a listing matched nothing
+	Suggestions []Article `json:"suggestions,omitempty"`
+}
+
+// ArticlesCountResponse reports how many articles match a listing's filters
+type ArticlesCountResponse struct {
+	ArticlesCount int `json:"articlesCount"`
+}
+
+// UnreadCountResponse reports how many feed articles arrived since a time
+type UnreadCountResponse struct {
+	Count int `json:"count"`
+}
+
+// ArticleSummary represents an article without its body, for lightweight list views
+type ArticleSummary struct {
+	ID              int       `json:"id"`
+	Slug            string    `json:"slug"`
+	Title           string    `json:"title"`
+	Description     string    `json:"description"`
+	CreatedAt       Timestamp `json:"createdAt"`
+	UpdatedAt       Timestamp `json:"updatedAt"`
+	Favorited       bool      `json:"favorited"`
+	FavoritesCount  int       `json:"favoritesCount"`
+	TagList         []string  `json:"tagList"`
+	Author          Profile   `json:"author"`
+	CoverImage      *string   `json:"coverImage"`
+	Version         int       `json:"version"`
+	CommentsEnabled bool      `json:"commentsEnabled"`
+	Published       bool      `json:"published"`
+	Pinned          bool      `json:"pinned,omitempty"`
+	Source          string    `json:"source,omitempty"`
+	Excerpt         string    `json:"excerpt,omitempty"`
+}
+
+// ArticleSummariesResponse represents the response format for multiple article summaries
+type ArticleSummariesResponse struct {
+	Articles      []ArticleSummary `json:"articles"`
+	ArticlesCount int              `json:"articlesCount"`
+	Suggestions   []ArticleSummary `json:"suggestions,omitempty"`
+}
+
+// ToSummary converts an Article to an ArticleSummary, dropping the body
+func (a *Article) ToSummary() ArticleSummary {
+	return ArticleSummary{
+		ID:              a.ID,
+		Slug:            a.Slug,
+		Title:           a.Title,
+		Description:     a.Description,
+		CreatedAt:       a.CreatedAt,
+		UpdatedAt:       a.UpdatedAt,
+		Favorited:       a.Favorited,
+		FavoritesCount:  a.FavoritesCount,
+		TagList:         a.TagList,
+		Author:          a.Author,
+		CoverImage:      a.CoverImage,
+		Version:         a.Version,
+		CommentsEnabled: a.CommentsEnabled,
+		Published:       a.Published,
+		Pinned:          a.Pinned,
+		Source:          a.Source,
+		Excerpt:         a.Excerpt,
+	}
+}
+
+// articleFields maps the names accepted by ?fields= to the value each one
+// selects from an article
+var articleFields = map[string]func(a *Article) interface{}{
+	"id":              func(a *Article) interface{} { return a.ID },
+	"slug":            func(a *Article) interface{} { return a.Slug },
+	"title":           func(a *Article) interface{} { return a.Title },
+	"description":     func(a *Article) interface{} { return a.Description },
+	"body":            func(a *Article) interface{} { return a.Body },
+	"createdAt":       func(a *Article) interface{} { return a.CreatedAt },
+	"updatedAt":       func(a *Article) interface{} { return a.UpdatedAt },
+	"favorited":       func(a *Article) interface{} { return a.Favorited },
+	"favoritesCount":  func(a *Article) interface{} { return a.FavoritesCount },
+	"tagList":         func(a *Article) interface{} { return a.TagList },
+	"author":          func(a *Article) interface{} { return a.Author },
+	"coverImage":      func(a *Article) interface{} { return a.CoverImage },
+	"version":         func(a *Article) interface{} { return a.Version },
+	"commentsEnabled": func(a *Article) interface{} { return a.CommentsEnabled },
+	"published":       func(a *Article) interface{} { return a.Published },
+	"pinned":          func(a *Article) interface{} { return a.Pinned },
+	"source":          func(a *Article) interface{} { return a.Source },
+	"excerpt":         func(a *Article) interface{} { return a.Excerpt },
+}
+
+// ParseArticleFields splits a comma-separated ?fields= value into the known
+// article fields it names, dropping unknown names and duplicates
+func ParseArticleFields(value string) []string {
+	var fields []string
+	seen := make(map[string]bool)
+	for _, name := range strings.Split(value, ",") {
+		name = strings.TrimSpace(name)
+		if articleFields[name] == nil || seen[name] {
+			continue
+		}
+		seen[name] = true
+		fields = append(fields, name)
+	}
+	return fields
+}
+
+// SelectFields returns only the named fields of an article, for partial
+// responses; names should come from ParseArticleFields
+func (a *Article) SelectFields(fields []string) map[string]interface{} {
+	selected := make(map[string]interface{}, len(fields))
+	for _, name := range fields {
+		// source is only meaningful in discover feeds, so it stays omitted
+		// elsewhere as in full responses
+		if name == "source" && a.Source == "" {
+			continue
+		}
+		selected[name] = articleFields[name](a)
+	}
+	return selected
+}
+
+// PartialArticlesResponse is a multiple-articles response carrying only the
+// fields requested with ?fields=
+type PartialArticlesResponse struct {
+	Articles      []map[string]interface{} `json:"articles"`
+	ArticlesCount int                      `json:"articlesCount"`
+	Suggestions   []map[string]interface{} `json:"suggestions,omitempty"`
 }
 
 // ArticleFilters represents filters for querying articles
 type ArticleFilters struct {
 	Tag        string `json:"tag"`
 	Author     string `json:"author"`
+	// Authors matches articles by any of several usernames
+	Authors    []string `json:"authors"`
 	Favorited  string `json:"favorited"`
+	// FavoritesOf lists one user's favorites, most recently favorited first
+	FavoritesOf int `json:"favoritesOf"`
+	// Popular lists published articles by recent favorites instead of newest first
+	Popular    bool   `json:"popular"`
+	// PinnedFirst puts an author's pinned article ahead of the rest
+	PinnedFirst bool `json:"pinnedFirst"`
+	// Sort is one of ArticleSorts; empty lists newest first
+	Sort       string `json:"sort"`
 	Limit      int    `json:"limit"`
 	Offset     int    `json:"offset"`
 }
 
+// MaxSlugLength matches the length Slugify trims generated slugs to
+const MaxSlugLength = 100
+
+// slugPattern matches lowercase words of letters and digits joined by single hyphens
+var slugPattern = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+
+// validateSlug checks a custom slug's format and length and that it doesn't
+// collide with a route keyword such as "feed"
+func validateSlug(slug string) ValidationErrors {
+	switch {
+	case len(slug) > MaxSlugLength:
+		return ValidationErrors{{"slug", "must be at most " + strconv.Itoa(MaxSlugLength) + " characters"}}
+	case !slugPattern.MatchString(slug):
+		return ValidationErrors{{"slug", "must be lowercase letters and digits separated by single hyphens"}}
+	case utils.IsReservedSlug(slug):
+		return ValidationErrors{{"slug", "is reserved"}}
+	}
+	return nil
+}
+
+// MaxTitleLength and MaxDescriptionLength cap an article's title and
+// description in bytes. The schema limits titles to 255 characters, so
+// MaxTitleLength can be lowered but not raised. Set from configuration at
+// startup.
+var (
+	MaxTitleLength       = 255
+	MaxDescriptionLength = 500
+)
+
+// tooLong reports a field over its configured length limit
+func tooLong(field string, max int) ValidationError {
+	return ValidationError{field, "must be at most " + strconv.Itoa(max) + " characters"}
+}
+
+// MaxArticleBodyLength caps an article body, in characters, to bound storage
+// and response sizes. Set from configuration at startup.
+var MaxArticleBodyLength = 100000
+
+// validateArticleBody checks a body against MaxArticleBodyLength and for
+// control characters
+func validateArticleBody(body string) ValidationErrors {
+	if utf8.RuneCountInString(body) > MaxArticleBodyLength {
+		return ValidationErrors{{"body", "must be at most " + strconv.Itoa(MaxArticleBodyLength) + " characters"}}
+	}
+	return validateText("body", body)
+}
+
 // Validate validates a CreateArticleRequest
 func (r *CreateArticleRequest) Validate() ValidationErrors {
 	var errors ValidationErrors
@@ -68,37 +333,40 @@ func (r *CreateArticleRequest) Validate() ValidationErrors {
 	if r.Article.Title == "" {
 		errors = append(errors, ValidationError{"title", "is required"})
 	} else {
-		if len(r.Article.Title) > 255 {
-			errors = append(errors, ValidationError{"title", "must be less than 255 characters"})
+		if len(r.Article.Title) > MaxTitleLength {
+			errors = append(errors, tooLong("title", MaxTitleLength))
 		}
+		errors = append(errors, validateText("title", r.Article.Title)...)
 	}
 
 	if r.Article.Description == "" {
 		errors = append(errors, ValidationError{"description", "is required"})
 	} else {
-		if len(r.Article.Description) > 500 {
-			errors = append(errors, ValidationError{"description", "must be less than 500 characters"})
+		if len(r.Article.Description) > MaxDescriptionLength {
+			errors = append(errors, tooLong("description", MaxDescriptionLength))
 		}
+		errors = append(errors, validateText("description", r.Article.Description)...)
 	}
 
 	if r.Article.Body == "" {
 		errors = append(errors, ValidationError{"body", "is required"})
+	} else {
+		errors = append(errors, validateArticleBody(r.Article.Body)...)
 	}
 
-	// Validate tags
-	if len(r.Article.TagList) > 10 {
-		errors = append(errors, ValidationError{"tagList", "cannot have more than 10 tags"})
+	// Cover image is optional
+	if r.Article.CoverImage != "" {
+		errors = append(errors, validateImageURL("coverImage", r.Article.CoverImage)...)
 	}
 
-	for _, tag := range r.Article.TagList {
-		if len(tag) > 50 {
-			errors = append(errors, ValidationError{"tagList", "each tag must be less than 50 characters"})
-		}
-		if tag == "" {
-			errors = append(errors, ValidationError{"tagList", "tags cannot be empty"})
-		}
+	// Slug is optional
+	if r.Article.Slug != "" {
+		errors = append(errors, validateSlug(r.Article.Slug)...)
 	}
 
+	// Validate tags
+	errors = append(errors, validateTagList(r.Article.TagList)...)
+
 	return errors
 }
 
@@ -106,26 +374,126 @@ func (r *CreateArticleRequest) Validate() ValidationErrors {
 func (r *UpdateArticleRequest) Validate() ValidationErrors {
 	var errors ValidationErrors
 
-	if r.Article.Title != "" && len(r.Article.Title) > 255 {
-		errors = append(errors, ValidationError{"title", "must be less than 255 characters"})
+	if r.Article.Title != "" && len(r.Article.Title) > MaxTitleLength {
+		errors = append(errors, tooLong("title", MaxTitleLength))
+	}
+	errors = append(errors, validateText("title", r.Article.Title)...)
+
+	if r.Article.Description != "" && len(r.Article.Description) > MaxDescriptionLength {
+		errors = append(errors, tooLong("description", MaxDescriptionLength))
+	}
+	errors = append(errors, validateText("description", r.Article.Description)...)
+
+	errors = append(errors, validateArticleBody(r.Article.Body)...)
+
+	if r.Article.CoverImage != "" {
+		errors = append(errors, validateImageURL("coverImage", r.Article.CoverImage)...)
+	}
+
+	if r.Article.Slug != "" {
+		errors = append(errors, validateSlug(r.Article.Slug)...)
+	}
+
+	// Validate tags if provided
+	errors = append(errors, validateTagList(r.Article.TagList)...)
+
+	return errors
+}
+
+// Validate validates a PatchArticleRequest
+func (r *PatchArticleRequest) Validate() ValidationErrors {
+	var errors ValidationErrors
+
+	if r.Article.Title != nil {
+		if *r.Article.Title == "" {
+			errors = append(errors, ValidationError{"title", "cannot be empty"})
+		} else if len(*r.Article.Title) > MaxTitleLength {
+			errors = append(errors, tooLong("title", MaxTitleLength))
+		} else {
+			errors = append(errors, validateText("title", *r.Article.Title)...)
+		}
+	}
+
+	// Description may be cleared, so only its length and content are checked
+	if r.Article.Description != nil {
+		if len(*r.Article.Description) > MaxDescriptionLength {
+			errors = append(errors, tooLong("description", MaxDescriptionLength))
+		}
+		errors = append(errors, validateText("description", *r.Article.Description)...)
+	}
+
+	if r.Article.Body != nil {
+		if *r.Article.Body == "" {
+			errors = append(errors, ValidationError{"body", "cannot be empty"})
+		} else {
+			errors = append(errors, validateArticleBody(*r.Article.Body)...)
+		}
 	}
 
-	if r.Article.Description != "" && len(r.Article.Description) > 500 {
-		errors = append(errors, ValidationError{"description", "must be less than 500 characters"})
+	// Cover image may be cleared with an empty value
+	if r.Article.CoverImage != nil && *r.Article.CoverImage != "" {
+		errors = append(errors, validateImageURL("coverImage", *r.Article.CoverImage)...)
+	}
+
+	if r.Article.Slug != nil {
+		if *r.Article.Slug == "" {
+			errors = append(errors, ValidationError{"slug", "cannot be empty"})
+		} else {
+			errors = append(errors, validateSlug(*r.Article.Slug)...)
+		}
 	}
 
 	// Validate tags if provided
-	if len(r.Article.TagList) > 10 {
+	errors = append(errors, validateTagList(r.Article.TagList)...)
+
+	return errors
+}
+
+// ToPatch converts a full update into a partial one, treating empty strings as "no change"
+func (r *UpdateArticleRequest) ToPatch() PatchArticleRequest {
+	var patch PatchArticleRequest
+
+	if r.Article.Title != "" {
+		patch.Article.Title = &r.Article.Title
+	}
+	if r.Article.Description != "" {
+		patch.Article.Description = &r.Article.Description
+	}
+	if r.Article.Body != "" {
+		patch.Article.Body = &r.Article.Body
+	}
+	if r.Article.CoverImage != "" {
+		patch.Article.CoverImage = &r.Article.CoverImage
+	}
+	if r.Article.Slug != "" {
+		patch.Article.Slug = &r.Article.Slug
+	}
+	patch.Article.TagList = r.Article.TagList
+	patch.Article.CommentsEnabled = r.Article.CommentsEnabled
+	patch.Article.Published = r.Article.Published
+	patch.Article.Version = r.Article.Version
+
+	return patch
+}
+
+// validateTagList validates the tags submitted with an article
+func validateTagList(tags []string) ValidationErrors {
+	var errors ValidationErrors
+
+	if len(tags) > 10 {
 		errors = append(errors, ValidationError{"tagList", "cannot have more than 10 tags"})
 	}
 
-	for _, tag := range r.Article.TagList {
+	for _, tag := range tags {
 		if len(tag) > 50 {
 			errors = append(errors, ValidationError{"tagList", "each tag must be less than 50 characters"})
 		}
 		if tag == "" {
 			errors = append(errors, ValidationError{"tagList", "tags cannot be empty"})
 		}
+		if hasControlChars(tag) {
+			errors = append(errors, ValidationError{"tagList", "tags must not contain control characters"})
+		}
 	}
 
 	return errors
@@ -136,4 +504,4 @@ var (
 	ErrArticleNotFound = errors.New("article not found")
 	ErrSlugExists      = errors.New("article with this slug already exists")
 	ErrNotAuthorized   = errors.New("not authorized to perform this action")
-)
\ No newline at end of file
+)
@@ -2,7 +2,8 @@ package models
 
 import (
 	"errors"
-	"time"
+	"regexp"
+	"strconv"
 )
 
 // Comment represents a comment in the system
@@ -11,11 +12,19 @@ type Comment struct {
 	Body      string    `json:"body" db:"body"`
 	AuthorID  int       `json:"-" db:"author_id"`
 	ArticleID int       `json:"-" db:"article_id"`
-	CreatedAt time.Time `json:"createdAt" db:"created_at"`
-	UpdatedAt time.Time `json:"updatedAt" db:"updated_at"`
+	CreatedAt Timestamp `json:"createdAt" db:"created_at"`
+	UpdatedAt Timestamp `json:"updatedAt" db:"updated_at"`
 	Author    Profile   `json:"author"`
+	// LikesCount and Liked (for the current user) reflect comment likes
+	LikesCount int  `json:"likesCount"`
+	Liked      bool `json:"liked"`
+	// Deleted marks a tombstone whose body has been replaced by DeletedCommentBody
+	Deleted bool `json:"deleted"`
 }
 
+// DeletedCommentBody replaces the body of a deleted comment in responses
+const DeletedCommentBody = "[deleted]"
+
 // CreateCommentRequest represents the request payload for creating a comment
 type CreateCommentRequest struct {
 	Comment struct {
@@ -23,6 +32,14 @@ type CreateCommentRequest struct {
 	} `json:"comment"`
 }
 
+// MaxCommentLinks rejects comments with more links than this, a cheap spam
+// filter; zero disables it. Set from configuration at startup.
+var MaxCommentLinks int
+
+// linkPattern matches the URLs counted against MaxCommentLinks: anything
+// starting with http://, https:// or www.
+var linkPattern = regexp.MustCompile(`(?i)\b(?:https?://|www\.)\S+`)
+
 // CommentResponse represents the response format for a single comment
 type CommentResponse struct {
 	Comment Comment `json:"comment"`
@@ -33,6 +50,47 @@ type CommentsResponse struct {
 	Comments []Comment `json:"comments"`
 }
 
+// MaxBulkDeleteComments caps how many comments one bulk delete may name
+const MaxBulkDeleteComments = 100
+
+// Bulk delete result statuses
+const (
+	CommentDeleteStatusDeleted   = "deleted"
+	CommentDeleteStatusNotFound  = "not_found"
+	CommentDeleteStatusForbidden = "forbidden"
+	// CommentDeleteStatusSkipped marks a deletable comment left alone because
+	// another id in the batch failed
+	CommentDeleteStatusSkipped = "skipped"
+)
+
+// DeleteCommentsRequest represents the request payload for a bulk comment delete
+type DeleteCommentsRequest struct {
+	IDs []int `json:"ids"`
+}
+
+// DeleteCommentsResponse reports the outcome for each requested id, in order
+type DeleteCommentsResponse struct {
+	Deleted int                   `json:"deleted"`
+	Results []CommentDeleteResult `json:"results"`
+}
+
+// CommentDeleteResult is the outcome for one id in a bulk delete
+type CommentDeleteResult struct {
+	ID     int    `json:"id"`
+	Status string `json:"status"`
+}
+
+// Validate validates a DeleteCommentsRequest
+func (r *DeleteCommentsRequest) Validate() ValidationErrors {
+	if len(r.IDs) == 0 {
+		return ValidationErrors{{"ids", "is required"}}
+	}
+	if len(r.IDs) > MaxBulkDeleteComments {
+		return ValidationErrors{{"ids", "cannot have more than " + strconv.Itoa(MaxBulkDeleteComments) + " ids"}}
+	}
+	return nil
+}
+
 // Validate validates a CreateCommentRequest
 func (r *CreateCommentRequest) Validate() ValidationErrors {
 	var errors ValidationErrors
@@ -43,6 +101,10 @@ func (r *CreateCommentRequest) Validate() ValidationErrors {
 		if len(r.Comment.Body) > 2000 {
 			errors = append(errors, ValidationError{"body", "must be less than 2000 characters"})
 		}
+		errors = append(errors, validateText("body", r.Comment.Body)...)
+		if MaxCommentLinks > 0 && len(linkPattern.FindAllStringIndex(r.Comment.Body, -1)) > MaxCommentLinks {
+			errors = append(errors, ValidationError{"body", "must contain at most " + strconv.Itoa(MaxCommentLinks) + " links"})
+		}
 	}
 
 	return errors
@@ -5,6 +5,10 @@ import (
 	"net/http"
 )
 
+// PrettyJSON indents response bodies for reading during development; set from
+// JSON_PRETTY at startup
+var PrettyJSON bool
+
 // ErrorResponse represents the standard error response format
 type ErrorResponse struct {
 	Errors map[string][]string `json:"errors"`
@@ -48,12 +52,21 @@ func WriteErrorResponse(w http.ResponseWriter, status int, err interface{}) {
 		response = NewErrorResponse("Internal server error")
 	}
 	
-	json.NewEncoder(w).Encode(response)
+	encodeJSON(w, response)
 }
 
 // WriteJSONResponse writes a JSON response to the HTTP response writer
 func WriteJSONResponse(w http.ResponseWriter, status int, data interface{}) {
 	w.Header().Set("Content-Type", "application/json; charset=utf-8")
 	w.WriteHeader(status)
-	json.NewEncoder(w).Encode(data)
+	encodeJSON(w, data)
+}
+
+// encodeJSON writes v to w, indented when PrettyJSON is set
+func encodeJSON(w http.ResponseWriter, v interface{}) {
+	encoder := json.NewEncoder(w)
+	if PrettyJSON {
+		encoder.SetIndent("", "  ")
+	}
+	encoder.Encode(v)
 }
\ No newline at end of file
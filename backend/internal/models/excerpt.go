@@ -0,0 +1,53 @@
+package models
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// ExcerptLength is how many characters of an article body list views show as
+// its excerpt; zero leaves excerpts out. Set from configuration at startup.
+var ExcerptLength = 200
+
+// Markdown syntax removed before an excerpt is cut
+var (
+	markdownFence   = regexp.MustCompile("(?m)^\\s*(```|~~~).*$")
+	markdownImage   = regexp.MustCompile(`!\[([^\]]*)\]\([^)]*\)`)
+	markdownLink    = regexp.MustCompile(`\[([^\]]*)\]\([^)]*\)`)
+	markdownLineTag = regexp.MustCompile(`(?m)^\s*(#{1,6}\s+|>\s?|[-*+]\s+|\d+\.\s+)`)
+	markdownInline  = regexp.MustCompile("[*_~`]+")
+)
+
+// Excerpt returns the start of body as plain text: markdown syntax is lightly
+// stripped, whitespace collapsed, and anything past ExcerptLength characters
+// cut at a word boundary and marked with an ellipsis
+func Excerpt(body string) string {
+	if ExcerptLength <= 0 {
+		return ""
+	}
+
+	text := markdownFence.ReplaceAllString(body, "")
+	text = markdownImage.ReplaceAllString(text, "$1")
+	text = markdownLink.ReplaceAllString(text, "$1")
+	text = markdownLineTag.ReplaceAllString(text, "")
+	text = markdownInline.ReplaceAllString(text, "")
+	text = strings.Join(strings.Fields(text), " ")
+
+	runes := []rune(text)
+	if len(runes) <= ExcerptLength {
+		return text
+	}
+
+	// Back up to the last space, unless a single word fills the whole excerpt
+	cut := ExcerptLength
+	for i := cut; i > 0; i-- {
+		if unicode.IsSpace(runes[i]) {
+			cut = i
+			break
+		}
+	}
+	return strings.TrimRightFunc(string(runes[:cut]), func(r rune) bool {
+		return unicode.IsSpace(r) || unicode.IsPunct(r)
+	}) + "…"
+}
@@ -0,0 +1,11 @@
+package models
+
+// HealthResponse represents the response format for the health check
+type HealthResponse struct {
+	Status    string `json:"status"`
+	Message   string `json:"message"`
+	Version   string `json:"version"`
+	Commit    string `json:"commit"`
+	BuildTime string `json:"buildTime"`
+	Uptime    string `json:"uptime"`
+}
@@ -0,0 +1,80 @@
+package models
+
+import (
+	"strconv"
+	"time"
+)
+
+// MaxImportArticles caps how many articles one import request may carry
+const MaxImportArticles = 100
+
+// Import result statuses
+const (
+	ImportStatusCreated = "created"
+	ImportStatusInvalid = "invalid"
+	// ImportStatusSkipped marks a valid article left out because another
+	// article in the batch was invalid
+	ImportStatusSkipped = "skipped"
+)
+
+// ImportArticlesRequest represents the request payload for a bulk import
+type ImportArticlesRequest struct {
+	Articles []ImportArticle `json:"articles"`
+}
+
+// ImportArticle is one article in a bulk import. Slug and CreatedAt are kept
+// when given so links and history survive a migration.
+type ImportArticle struct {
+	Slug            string     `json:"slug"`
+	Title           string     `json:"title"`
+	Description     string     `json:"description"`
+	Body            string     `json:"body"`
+	TagList         []string   `json:"tagList"`
+	CoverImage      string     `json:"coverImage"`
+	CommentsEnabled *bool      `json:"commentsEnabled"`
+	CreatedAt       *Timestamp `json:"createdAt"`
+}
+
+// ImportArticlesResponse reports the outcome for each submitted article, in order
+type ImportArticlesResponse struct {
+	Imported int            `json:"imported"`
+	Results  []ImportResult `json:"results"`
+}
+
+// ImportResult is the outcome for one imported article
+type ImportResult struct {
+	Index  int                 `json:"index"`
+	Slug   string              `json:"slug,omitempty"`
+	Status string              `json:"status"`
+	Errors map[string][]string `json:"errors,omitempty"`
+}
+
+// Validate validates the batch as a whole; each article is checked separately
+func (r *ImportArticlesRequest) Validate() ValidationErrors {
+	if len(r.Articles) == 0 {
+		return ValidationErrors{{"articles", "is required"}}
+	}
+	if len(r.Articles) > MaxImportArticles {
+		return ValidationErrors{{"articles", "cannot have more than " + strconv.Itoa(MaxImportArticles) + " articles"}}
+	}
+	return nil
+}
+
+// Validate applies the same rules as creating an article, plus checks on the
+// preset slug and creation time
+func (a *ImportArticle) Validate() ValidationErrors {
+	var create CreateArticleRequest
+	create.Article.Title = a.Title
+	create.Article.Description = a.Description
+	create.Article.Body = a.Body
+	create.Article.TagList = a.TagList
+	create.Article.CoverImage = a.CoverImage
+	create.Article.Slug = a.Slug
+	errors := create.Validate()
+
+	if a.CreatedAt != nil && a.CreatedAt.After(time.Now().Add(time.Minute)) {
+		errors = append(errors, ValidationError{"createdAt", "cannot be in the future"})
+	}
+
+	return errors
+}
@@ -0,0 +1,51 @@
+package models
+
+// Invite batch limits
+const (
+	MaxInvitesPerRequest  = 100
+	DefaultInviteTTLHours = 168
+	MaxInviteTTLHours     = 8760
+)
+
+// Invite represents a single-use signup code
+type Invite struct {
+	Code      string     `json:"code"`
+	CreatedAt Timestamp  `json:"createdAt"`
+	ExpiresAt *Timestamp `json:"expiresAt"`
+	UsedAt    *Timestamp `json:"usedAt"`
+	// UsedBy is the username of the account the code created
+	UsedBy *string `json:"usedBy"`
+}
+
+// CreateInvitesRequest represents the request payload for generating invite codes
+type CreateInvitesRequest struct {
+	Count          int  `json:"count"`
+	ExpiresInHours *int `json:"expiresInHours"`
+}
+
+// InvitesResponse represents the response format for a list of invite codes
+type InvitesResponse struct {
+	Invites []Invite `json:"invites"`
+}
+
+// Validate validates a CreateInvitesRequest, applying defaults for omitted fields
+func (r *CreateInvitesRequest) Validate() ValidationErrors {
+	var errors ValidationErrors
+
+	if r.Count == 0 {
+		r.Count = 1
+	}
+	if r.Count < 1 || r.Count > MaxInvitesPerRequest {
+		errors = append(errors, ValidationError{"count", "must be between 1 and 100"})
+	}
+
+	if r.ExpiresInHours == nil {
+		ttl := DefaultInviteTTLHours
+		r.ExpiresInHours = &ttl
+	}
+	if *r.ExpiresInHours < 1 || *r.ExpiresInHours > MaxInviteTTLHours {
+		errors = append(errors, ValidationError{"expiresInHours", "must be between 1 and 8760"})
+	}
+
+	return errors
+}
@@ -0,0 +1,27 @@
+package models
+
+import "encoding/json"
+
+// OptionalString is a JSON string field that tells an absent key, an
+// explicit null and a value apart, for updates where omission means
+// "unchanged" and null means "clear"
+type OptionalString struct {
+	// Present is set when the key appeared in the object, even as null
+	Present bool
+	// Null is set for an explicit null; Value is then empty
+	Null  bool
+	Value string
+}
+
+// UnmarshalJSON records that the key was present. encoding/json only calls it
+// for keys in the input, null included.
+func (o *OptionalString) UnmarshalJSON(data []byte) error {
+	o.Present = true
+	if string(data) == "null" {
+		o.Null = true
+		o.Value = ""
+		return nil
+	}
+	o.Null = false
+	return json.Unmarshal(data, &o.Value)
+}
@@ -0,0 +1,42 @@
+package models
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestOptionalStringUnmarshal(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  OptionalString
+	}{
+		{"omitted", `{}`, OptionalString{}},
+		{"null", `{"bio":null}`, OptionalString{Present: true, Null: true}},
+		{"empty", `{"bio":""}`, OptionalString{Present: true}},
+		{"value", `{"bio":"hello"}`, OptionalString{Present: true, Value: "hello"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got struct {
+				Bio OptionalString `json:"bio"`
+			}
+			if err := json.Unmarshal([]byte(tt.input), &got); err != nil {
+				t.Fatalf("Unmarshal(%s): %v", tt.input, err)
+			}
+			if got.Bio != tt.want {
+				t.Errorf("Unmarshal(%s) = %+v, want %+v", tt.input, got.Bio, tt.want)
+			}
+		})
+	}
+}
+
+func TestOptionalStringRejectsNonString(t *testing.T) {
+	var got struct {
+		Bio OptionalString `json:"bio"`
+	}
+	if err := json.Unmarshal([]byte(`{"bio":42}`), &got); err == nil {
+		t.Errorf("Unmarshal accepted a number, got %+v", got.Bio)
+	}
+}
@@ -0,0 +1,16 @@
+package models
+
+// Session represents one login and the tokens refreshed from it
+type Session struct {
+	ID         string    `json:"id"`
+	CreatedAt  Timestamp `json:"createdAt"`
+	LastSeenAt Timestamp `json:"lastSeenAt"`
+	ExpiresAt  Timestamp `json:"expiresAt"`
+	// Current marks the session the request was made with
+	Current bool `json:"current"`
+}
+
+// SessionsResponse represents the response format for a user's sessions
+type SessionsResponse struct {
+	Sessions []Session `json:"sessions"`
+}
@@ -0,0 +1,16 @@
+package models
+
+// StatsResponse represents the response format for site-wide totals
+type StatsResponse struct {
+	Stats Stats `json:"stats"`
+}
+
+// Stats holds aggregate counts for a public dashboard
+type Stats struct {
+	Users           int       `json:"users"`
+	Articles        int       `json:"articles"`
+	Comments        int       `json:"comments"`
+	Tags            int       `json:"tags"`
+	ArticlesLast24h int       `json:"articlesLast24h"`
+	GeneratedAt     Timestamp `json:"generatedAt"`
+}
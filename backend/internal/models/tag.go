@@ -9,4 +9,22 @@ type Tag struct {
 // TagsResponse represents the response format for tags
 type TagsResponse struct {
 	Tags []string `json:"tags"`
-}
\ No newline at end of file
+}
+
+// TagsPageResponse represents one page of the tag list
+type TagsPageResponse struct {
+	Tags []string `json:"tags"`
+	// TagsCount is the number of tags in use, across all pages
+	TagsCount int `json:"tagsCount"`
+}
+
+// TrendingTagsResponse represents the response format for trending tags
+type TrendingTagsResponse struct {
+	Tags []TrendingTag `json:"tags"`
+}
+
+// TrendingTag is a tag with the favorites its articles gained recently
+type TrendingTag struct {
+	Tag       string `json:"tag"`
+	Favorites int    `json:"favorites"`
+}
@@ -0,0 +1,26 @@
+package models
+
+import "unicode"
+
+// hasControlChars reports whether s contains control characters other than
+// newline, carriage return, and tab. Null bytes and other controls break
+// some clients, so free-text fields reject them.
+func hasControlChars(s string) bool {
+	for _, r := range s {
+		if r == '\n' || r == '\r' || r == '\t' {
+			continue
+		}
+		if unicode.IsControl(r) {
+			return true
+		}
+	}
+	return false
+}
+
+// validateText rejects free text containing disallowed control characters
+func validateText(field, value string) ValidationErrors {
+	if hasControlChars(value) {
+		return ValidationErrors{{field, "must not contain control characters"}}
+	}
+	return nil
+}
@@ -0,0 +1,52 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Timestamp is a time.Time that is always normalized to UTC and serialized
+// as RFC3339, so clients see the same format regardless of driver or host zone
+type Timestamp struct {
+	time.Time
+}
+
+// NewTimestamp creates a Timestamp normalized to UTC
+func NewTimestamp(t time.Time) Timestamp {
+	return Timestamp{t.UTC()}
+}
+
+// MarshalJSON encodes the timestamp as an RFC3339 UTC string
+func (t Timestamp) MarshalJSON() ([]byte, error) {
+	return json.Marshal(t.UTC().Format(time.RFC3339))
+}
+
+// UnmarshalJSON decodes an RFC3339 string into a UTC timestamp
+func (t *Timestamp) UnmarshalJSON(data []byte) error {
+	var parsed time.Time
+	if err := json.Unmarshal(data, &parsed); err != nil {
+		return err
+	}
+	t.Time = parsed.UTC()
+	return nil
+}
+
+// Scan implements sql.Scanner, converting database times to UTC
+func (t *Timestamp) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case time.Time:
+		t.Time = v.UTC()
+	case nil:
+		t.Time = time.Time{}
+	default:
+		return fmt.Errorf("cannot scan %T into Timestamp", src)
+	}
+	return nil
+}
+
+// Value implements driver.Valuer, storing the timestamp in UTC
+func (t Timestamp) Value() (driver.Value, error) {
+	return t.UTC(), nil
+}
@@ -0,0 +1,73 @@
+package models
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestTimestampMarshalJSON(t *testing.T) {
+	zone := time.FixedZone("UTC+2", 2*60*60)
+	ts := Timestamp{time.Date(2024, 3, 1, 14, 30, 15, 500, zone)}
+
+	data, err := json.Marshal(ts)
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+	if got, want := string(data), `"2024-03-01T12:30:15Z"`; got != want {
+		t.Errorf("Marshal = %s, want %s", got, want)
+	}
+}
+
+func TestTimestampUnmarshalJSON(t *testing.T) {
+	var ts Timestamp
+	if err := json.Unmarshal([]byte(`"2024-03-01T14:30:15+02:00"`), &ts); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	if ts.Location() != time.UTC {
+		t.Errorf("location = %s, want UTC", ts.Location())
+	}
+	if want := time.Date(2024, 3, 1, 12, 30, 15, 0, time.UTC); !ts.Equal(want) {
+		t.Errorf("Unmarshal = %s, want %s", ts, want)
+	}
+
+	if err := json.Unmarshal([]byte(`"yesterday"`), &ts); err == nil {
+		t.Error("Unmarshal accepted a non-RFC3339 string")
+	}
+}
+
+func TestTimestampScan(t *testing.T) {
+	zone := time.FixedZone("UTC-5", -5*60*60)
+	src := time.Date(2024, 3, 1, 7, 30, 15, 0, zone)
+
+	var ts Timestamp
+	if err := ts.Scan(src); err != nil {
+		t.Fatalf("Scan(time.Time): %v", err)
+	}
+	if ts.Location() != time.UTC || !ts.Equal(src) {
+		t.Errorf("Scan = %s, want %s in UTC", ts, src)
+	}
+
+	if err := ts.Scan(nil); err != nil {
+		t.Fatalf("Scan(nil): %v", err)
+	}
+	if !ts.IsZero() {
+		t.Errorf("Scan(nil) = %s, want the zero time", ts)
+	}
+
+	if err := ts.Scan("2024-03-01"); err == nil {
+		t.Error("Scan accepted a string")
+	}
+}
+
+func TestTimestampValue(t *testing.T) {
+	zone := time.FixedZone("UTC+2", 2*60*60)
+	v, err := NewTimestamp(time.Date(2024, 3, 1, 14, 30, 15, 0, zone)).Value()
+	if err != nil {
+		t.Fatalf("Value: %v", err)
+	}
+	got, ok := v.(time.Time)
+	if !ok || got.Location() != time.UTC {
+		t.Errorf("Value = %#v, want a UTC time.Time", v)
+	}
+}
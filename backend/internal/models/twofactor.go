@@ -0,0 +1,29 @@
+package models
+
+// TwoFactorEnrollResponse represents the response format for starting 2FA enrollment
+type TwoFactorEnrollResponse struct {
+	Secret     string `json:"secret"`
+	OtpauthURL string `json:"otpauthUrl"`
+}
+
+// TwoFactorConfirmRequest represents the request payload for confirming 2FA enrollment
+type TwoFactorConfirmRequest struct {
+	Code string `json:"code"`
+}
+
+// TwoFactorConfirmResponse represents the response format after 2FA is enabled.
+// Recovery codes are only ever shown here, once.
+type TwoFactorConfirmResponse struct {
+	RecoveryCodes []string `json:"recoveryCodes"`
+}
+
+// Validate validates a TwoFactorConfirmRequest
+func (r *TwoFactorConfirmRequest) Validate() ValidationErrors {
+	var errors ValidationErrors
+
+	if r.Code == "" {
+		errors = append(errors, ValidationError{"code", "is required"})
+	}
+
+	return errors
+}
@@ -2,9 +2,10 @@ package models
 
 import (
 	"errors"
+	"net/mail"
+	"net/url"
 	"regexp"
 	"strings"
-	"time"
 )
 
 // User represents a user in the system
@@ -14,8 +15,8 @@ type User struct {
 	Email     string    `json:"email" db:"email"`
 	Bio       string    `json:"bio" db:"bio"`
 	Image     string    `json:"image" db:"image"`
-	CreatedAt time.Time `json:"createdAt" db:"created_at"`
-	UpdatedAt time.Time `json:"updatedAt" db:"updated_at"`
+	CreatedAt Timestamp `json:"createdAt" db:"created_at"`
+	UpdatedAt Timestamp `json:"updatedAt" db:"updated_at"`
 }
 
 // Profile represents a user profile (public view)
@@ -24,6 +25,7 @@ type Profile struct {
 	Bio       string `json:"bio"`
 	Image     string `json:"image"`
 	Following bool   `json:"following"`
+	Blocked   bool   `json:"blocked,omitempty"`
 }
 
 // RegisterRequest represents the request payload for user registration
@@ -32,14 +34,18 @@ type RegisterRequest struct {
 		Username string `json:"username"`
 		Email    string `json:"email"`
 		Password string `json:"password"`
+		// InviteCode allows signup while registration is closed
+		InviteCode string `json:"inviteCode,omitempty"`
 	} `json:"user"`
 }
 
 // LoginRequest represents the request payload for user login
 type LoginRequest struct {
 	User struct {
-		Email    string `json:"email"`
-		Password string `json:"password"`
+		Email        string `json:"email"`
+		Password     string `json:"password"`
+		TOTPCode     string `json:"totpCode,omitempty"`
+		RecoveryCode string `json:"recoveryCode,omitempty"`
 	} `json:"user"`
 }
 
@@ -49,8 +55,10 @@ type UpdateUserRequest struct {
 		Username string `json:"username,omitempty"`
 		Email    string `json:"email,omitempty"`
 		Password string `json:"password,omitempty"`
-		Bio      string `json:"bio,omitempty"`
-		Image    string `json:"image,omitempty"`
+		// Bio and Image are left unchanged when absent and cleared by null
+		// or an empty string
+		Bio   OptionalString `json:"bio"`
+		Image OptionalString `json:"image"`
 	} `json:"user"`
 }
 
@@ -66,11 +74,50 @@ type UserData struct {
 	Bio      string `json:"bio"`
 	Image    string `json:"image"`
 	Token    string `json:"token"`
+	// LastLoginAt is only reported to the user themselves, by GET /api/user
+	LastLoginAt *Timestamp `json:"lastLoginAt,omitempty"`
 }
 
 // ProfileResponse represents the response format for profile data
 type ProfileResponse struct {
 	Profile Profile `json:"profile"`
+	// Changed reports whether a follow/unfollow altered state; only set when requested
+	Changed *bool `json:"changed,omitempty"`
+	// Stats is only set when GET /api/profiles/{username}?include=stats asks for it
+	Stats *ProfileStats `json:"stats,omitempty"`
+}
+
+// ProfileStats summarizes a user's activity; drafts are not counted
+type ProfileStats struct {
+	ArticlesCount     int `json:"articlesCount"`
+	FollowersCount    int `json:"followersCount"`
+	FavoritesReceived int `json:"favoritesReceived"`
+}
+
+// UserChange represents an audited change to a user's email or username
+type UserChange struct {
+	Field     string    `json:"field" db:"field"`
+	OldValue  string    `json:"oldValue" db:"old_value"`
+	NewValue  string    `json:"newValue" db:"new_value"`
+	ChangedAt Timestamp `json:"changedAt" db:"changed_at"`
+}
+
+// SecurityLogResponse represents the response format for a user's security log
+type SecurityLogResponse struct {
+	Changes []UserChange `json:"changes"`
+}
+
+// ProfilesResponse represents the response format for a batch profile lookup
+type ProfilesResponse struct {
+	Profiles []Profile `json:"profiles"`
+	NotFound []string  `json:"notFound"`
+}
+
+// AvailabilityResponse reports whether a username and email are free to
+// register; fields that weren't asked about are omitted
+type AvailabilityResponse struct {
+	UsernameAvailable *bool `json:"usernameAvailable,omitempty"`
+	EmailAvailable    *bool `json:"emailAvailable,omitempty"`
 }
 
 // ValidationError represents a field validation error
@@ -101,16 +148,7 @@ func (r *RegisterRequest) Validate() ValidationErrors {
 	if r.User.Username == "" {
 		errors = append(errors, ValidationError{"username", "is required"})
 	} else {
-		if len(r.User.Username) < 3 {
-			errors = append(errors, ValidationError{"username", "must be at least 3 characters long"})
-		}
-		if len(r.User.Username) > 50 {
-			errors = append(errors, ValidationError{"username", "must be less than 50 characters"})
-		}
-		// Check for valid characters (alphanumeric, underscore, hyphen)
-		if matched, _ := regexp.MatchString(`^[a-zA-Z0-9_-]+$`, r.User.Username); !matched {
-			errors = append(errors, ValidationError{"username", "can only contain letters, numbers, underscores, and hyphens"})
-		}
+		errors = append(errors, Usernames.Validate(r.User.Username)...)
 	}
 
 	// Email validation
@@ -137,7 +175,10 @@ func (r *RegisterRequest) Validate() ValidationErrors {
 	return errors
 }
 
-// Validate validates a LoginRequest
+// Validate validates a LoginRequest. It only checks that the input is well
+// formed; password policy is deliberately not applied here so a login attempt
+// can't be used to probe the rules, and credential mismatches are left to the
+// handler's generic 401.
 func (l *LoginRequest) Validate() ValidationErrors {
 	var errors ValidationErrors
 
@@ -162,15 +203,7 @@ func (u *UpdateUserRequest) Validate() ValidationErrors {
 
 	// Username validation (optional)
 	if u.User.Username != "" {
-		if len(u.User.Username) < 3 {
-			errors = append(errors, ValidationError{"username", "must be at least 3 characters long"})
-		}
-		if len(u.User.Username) > 50 {
-			errors = append(errors, ValidationError{"username", "must be less than 50 characters"})
-		}
-		if matched, _ := regexp.MatchString(`^[a-zA-Z0-9_-]+$`, u.User.Username); !matched {
-			errors = append(errors, ValidationError{"username", "can only contain letters, numbers, underscores, and hyphens"})
-		}
+		errors = append(errors, Usernames.Validate(u.User.Username)...)
 	}
 
 	// Email validation (optional)
@@ -191,30 +224,39 @@ func (u *UpdateUserRequest) Validate() ValidationErrors {
 	}
 
 	// Bio validation (optional)
-	if len(u.User.Bio) > 1000 {
+	if len(u.User.Bio.Value) > 1000 {
 		errors = append(errors, ValidationError{"bio", "must be less than 1000 characters"})
 	}
+	errors = append(errors, validateText("bio", u.User.Bio.Value)...)
 
 	// Image URL validation (optional)
-	if u.User.Image != "" {
-		if len(u.User.Image) > 500 {
-			errors = append(errors, ValidationError{"image", "URL must be less than 500 characters"})
-		}
-		if !isValidURL(u.User.Image) {
-			errors = append(errors, ValidationError{"image", "must be a valid URL"})
-		}
+	if u.User.Image.Value != "" {
+		errors = append(errors, validateImageURL("image", u.User.Image.Value)...)
 	}
 
 	return errors
 }
 
+// DefaultAvatarURL is served in place of an empty user image so clients share
+// one placeholder. Stored images stay empty. Set from configuration at startup.
+var DefaultAvatarURL string
+
+// DisplayImage returns the image to serialize for a user, falling back to
+// DefaultAvatarURL when none is set
+func DisplayImage(image string) string {
+	if image == "" {
+		return DefaultAvatarURL
+	}
+	return image
+}
+
 // ToUserData converts a User model to UserData for API responses
 func (u *User) ToUserData(token string) UserData {
 	return UserData{
 		Username: u.Username,
 		Email:    u.Email,
 		Bio:      u.Bio,
-		Image:    u.Image,
+		Image:    DisplayImage(u.Image),
 		Token:    token,
 	}
 }
@@ -224,15 +266,85 @@ func (u *User) ToProfile(following bool) Profile {
 	return Profile{
 		Username:  u.Username,
 		Bio:       u.Bio,
-		Image:     u.Image,
+		Image:     DisplayImage(u.Image),
 		Following: following,
 	}
 }
 
+// maxEmailLength is the longest address SMTP can carry
+const maxEmailLength = 254
+
+// StrictEmailValidation checks emails against RFC 5322 with net/mail instead
+// of the simpler default pattern. Set from configuration at startup.
+var StrictEmailValidation bool
+
+var emailRegex = regexp.MustCompile(`^[a-zA-Z0-9._%+\-]+@[a-zA-Z0-9.\-]+\.[a-zA-Z]{2,}$`)
+
 // Helper function to validate email format
 func isValidEmail(email string) bool {
-	emailRegex := regexp.MustCompile(`^[a-zA-Z0-9._%+\-]+@[a-zA-Z0-9.\-]+\.[a-zA-Z]{2,}$`)
-	return emailRegex.MatchString(email) && len(email) <= 254
+	if len(email) > maxEmailLength {
+		return false
+	}
+	if !StrictEmailValidation {
+		return emailRegex.MatchString(email)
+	}
+
+	// ParseAddress also accepts "Name <addr>" and comments; only a bare
+	// address that parses back to itself is taken. The users table's
+	// email_format check still wants a dot in the domain, as does delivery
+	// outside a private network.
+	addr, err := mail.ParseAddress(email)
+	if err != nil || addr.Name != "" || addr.Address != email {
+		return false
+	}
+	at := strings.LastIndex(email, "@")
+	return strings.Contains(email[at+1:], ".")
+}
+
+// RequireHTTPSImages makes image URL validation reject anything but https,
+// avoiding mixed-content warnings. Set from configuration at startup.
+var RequireHTTPSImages bool
+
+// AllowedImageHosts, when non-empty, limits image URLs to these lower-case
+// hosts; an entry like "*.example.com" also matches its subdomains. Set from
+// configuration at startup.
+var AllowedImageHosts []string
+
+// validateImageURL validates an image URL field shared by users and articles
+func validateImageURL(field, value string) ValidationErrors {
+	var errors ValidationErrors
+
+	if len(value) > 500 {
+		errors = append(errors, ValidationError{field, "URL must be less than 500 characters"})
+	}
+	if !isValidURL(value) {
+		errors = append(errors, ValidationError{field, "must be a valid URL"})
+	} else if RequireHTTPSImages && !strings.HasPrefix(strings.ToLower(value), "https://") {
+		errors = append(errors, ValidationError{field, "must be an https URL"})
+	} else if !imageHostAllowed(value) {
+		errors = append(errors, ValidationError{field, "must be hosted on an allowed domain"})
+	}
+
+	return errors
+}
+
+// imageHostAllowed reports whether an image URL's host is in AllowedImageHosts
+func imageHostAllowed(rawURL string) bool {
+	if len(AllowedImageHosts) == 0 {
+		return true
+	}
+
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return false
+	}
+	host := strings.ToLower(u.Hostname())
+	for _, allowed := range AllowedImageHosts {
+		if host == allowed || (strings.HasPrefix(allowed, "*.") && strings.HasSuffix(host, allowed[1:])) {
+			return true
+		}
+	}
+	return false
 }
 
 // Helper function to validate URL format
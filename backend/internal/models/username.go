@@ -0,0 +1,64 @@
+package models
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// Username lengths allowed by the users table's username_length constraint;
+// a policy can tighten these bounds but not widen them
+const (
+	UsernameMinLengthLimit = 3
+	UsernameMaxLengthLimit = 50
+)
+
+var usernameCharsPattern = regexp.MustCompile(`^[a-zA-Z0-9_-]+$`)
+
+// UsernamePolicy holds the rules shared by registration and profile updates
+type UsernamePolicy struct {
+	MinLength          int
+	MaxLength          int
+	RequireLetterStart bool
+}
+
+// Usernames is the active username policy. Set from configuration at startup.
+var Usernames = UsernamePolicy{
+	MinLength: UsernameMinLengthLimit,
+	MaxLength: UsernameMaxLengthLimit,
+}
+
+// Check reports whether the policy itself is usable with the database schema
+func (p UsernamePolicy) Check() error {
+	if p.MinLength < UsernameMinLengthLimit || p.MaxLength > UsernameMaxLengthLimit {
+		return fmt.Errorf("username lengths must be within %d-%d", UsernameMinLengthLimit, UsernameMaxLengthLimit)
+	}
+	if p.MinLength > p.MaxLength {
+		return fmt.Errorf("minimum username length %d exceeds maximum %d", p.MinLength, p.MaxLength)
+	}
+	return nil
+}
+
+// Validate checks a username against the policy
+func (p UsernamePolicy) Validate(username string) ValidationErrors {
+	var errors ValidationErrors
+
+	if len(username) < p.MinLength {
+		errors = append(errors, ValidationError{"username", fmt.Sprintf("must be at least %d characters long", p.MinLength)})
+	}
+	if len(username) > p.MaxLength {
+		errors = append(errors, ValidationError{"username", fmt.Sprintf("must be at most %d characters long", p.MaxLength)})
+	}
+	// Check for valid characters (alphanumeric, underscore, hyphen)
+	if !usernameCharsPattern.MatchString(username) {
+		errors = append(errors, ValidationError{"username", "can only contain letters, numbers, underscores, and hyphens"})
+	}
+	if p.RequireLetterStart && username != "" && !isASCIILetter(username[0]) {
+		errors = append(errors, ValidationError{"username", "must start with a letter"})
+	}
+
+	return errors
+}
+
+func isASCIILetter(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
@@ -0,0 +1,84 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLocalPutGetDelete(t *testing.T) {
+	ctx := context.Background()
+	dir := filepath.Join(t.TempDir(), "uploads")
+	store, err := NewLocal(dir, "/api/uploads/")
+	if err != nil {
+		t.Fatalf("NewLocal: %v", err)
+	}
+
+	if err := store.Put(ctx, "avatar.png", []byte("image data"), "image/png"); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+
+	rc, err := store.Get(ctx, "avatar.png")
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	data, err := io.ReadAll(rc)
+	rc.Close()
+	if err != nil {
+		t.Fatalf("reading object: %v", err)
+	}
+	if string(data) != "image data" {
+		t.Errorf("Get returned %q, want %q", data, "image data")
+	}
+
+	// Put leaves no temp files behind
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("ReadDir: %v", err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("storage directory holds %d entries, want 1", len(entries))
+	}
+
+	if got, want := store.URL("avatar.png"), "/api/uploads/avatar.png"; got != want {
+		t.Errorf("URL = %q, want %q", got, want)
+	}
+
+	if err := store.Delete(ctx, "avatar.png"); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if _, err := store.Get(ctx, "avatar.png"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Get after Delete: err = %v, want ErrNotFound", err)
+	}
+	if err := store.Delete(ctx, "avatar.png"); err != nil {
+		t.Errorf("deleting a missing object: %v", err)
+	}
+}
+
+func TestLocalRejectsInvalidKeys(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	store, err := NewLocal(filepath.Join(dir, "uploads"), "/api/uploads")
+	if err != nil {
+		t.Fatalf("NewLocal: %v", err)
+	}
+
+	for _, key := range []string{"", "../x", "a/b", ".hidden", "..", "."} {
+		if err := store.Put(ctx, key, []byte("x"), "text/plain"); err == nil {
+			t.Errorf("Put(%q) succeeded, want an error", key)
+		}
+		if _, err := store.Get(ctx, key); err == nil || errors.Is(err, ErrNotFound) {
+			t.Errorf("Get(%q): err = %v, want an invalid key error", key, err)
+		}
+		if err := store.Delete(ctx, key); err == nil {
+			t.Errorf("Delete(%q) succeeded, want an error", key)
+		}
+	}
+
+	if _, err := os.Stat(filepath.Join(dir, "x")); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("Put(\"../x\") wrote outside the storage directory")
+	}
+}
@@ -0,0 +1,23 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+)
+
+// ErrNotFound is returned when a stored object doesn't exist
+var ErrNotFound = errors.New("object not found")
+
+// Storage stores uploaded files such as avatar images
+type Storage interface {
+	// Put stores data under key, replacing any existing object
+	Put(ctx context.Context, key string, data []byte, contentType string) error
+	// Get opens the object stored under key, returning ErrNotFound if missing
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	// Delete removes the object stored under key; deleting a missing object is not an error
+	Delete(ctx context.Context, key string) error
+	// URL returns where clients can fetch the object. A URL starting with "/"
+	// is relative to the API's own base URL.
+	URL(key string) string
+}
@@ -1,43 +1,144 @@
 package utils
 
 import (
+	"crypto/rsa"
 	"errors"
+	"fmt"
+	"os"
+	"strings"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
 )
 
+// TokenClaimsConfig sets the issuer and audience written into tokens and
+// required of them on validation
+type TokenClaimsConfig struct {
+	Issuer string
+	// Audience is omitted from tokens and not checked when empty
+	Audience string
+	// AllowMissing accepts tokens lacking an iss or aud claim, so tokens
+	// issued before the claims were configured keep working during rollout.
+	// Tokens carrying a different issuer or audience are still rejected.
+	AllowMissing bool
+}
+
+// TokenClaims is the active issuer and audience configuration. Set from
+// configuration at startup. Tokens issued before iss was written have none,
+// so they are accepted until the grace flag is turned off.
+var TokenClaims = TokenClaimsConfig{Issuer: "realworld-api", AllowMissing: true}
+
+// Supported token signing algorithms
+const (
+	SigningHS256 = "HS256"
+	SigningRS256 = "RS256"
+)
+
+// TokenSigningConfig selects how tokens are signed and verified. HS256 uses
+// the shared JWT secret; RS256 signs with a private key so other services
+// can verify tokens holding only the public key.
+type TokenSigningConfig struct {
+	Algorithm  string
+	PrivateKey *rsa.PrivateKey
+}
+
+// TokenSigning is the active signing configuration. Set from configuration
+// at startup.
+var TokenSigning = TokenSigningConfig{Algorithm: SigningHS256}
+
+// minRSAKeyBits rejects keys too small to sign tokens safely
+const minRSAKeyBits = 2048
+
+// NewTokenSigning builds a signing configuration, loading and checking the
+// PEM private key (PKCS#1 or PKCS#8) that RS256 requires
+func NewTokenSigning(algorithm, privateKeyPath string) (TokenSigningConfig, error) {
+	switch strings.ToUpper(algorithm) {
+	case SigningHS256:
+		return TokenSigningConfig{Algorithm: SigningHS256}, nil
+	case SigningRS256:
+		if privateKeyPath == "" {
+			return TokenSigningConfig{}, errors.New("RS256 requires a private key file")
+		}
+		pemBytes, err := os.ReadFile(privateKeyPath)
+		if err != nil {
+			return TokenSigningConfig{}, fmt.Errorf("reading private key: %w", err)
+		}
+		key, err := jwt.ParseRSAPrivateKeyFromPEM(pemBytes)
+		if err != nil {
+			return TokenSigningConfig{}, fmt.Errorf("parsing private key: %w", err)
+		}
+		if key.N.BitLen() < minRSAKeyBits {
+			return TokenSigningConfig{}, fmt.Errorf("private key is %d bits; at least %d are required", key.N.BitLen(), minRSAKeyBits)
+		}
+		return TokenSigningConfig{Algorithm: SigningRS256, PrivateKey: key}, nil
+	default:
+		return TokenSigningConfig{}, fmt.Errorf("unsupported algorithm %q (want HS256 or RS256)", algorithm)
+	}
+}
+
 type Claims struct {
 	UserID   int    `json:"user_id"`
 	Username string `json:"username"`
+	// SessionID is shared by every token refreshed from the same login
+	SessionID string `json:"sid,omitempty"`
 	jwt.RegisteredClaims
 }
 
-// GenerateToken creates a new JWT token for a user
-func GenerateToken(userID int, username, secret string) (string, error) {
+// tokenIDLength is the length of the random jti given to each token
+const tokenIDLength = 16
+
+// TokenLifetime is how long a token stays valid after it is issued
+const TokenLifetime = 7 * 24 * time.Hour
+
+// NewSessionID returns a random id for a new login session, in the same form
+// as a jti
+func NewSessionID() string {
+	return randomBase62(tokenIDLength)
+}
+
+// GenerateToken creates a new JWT token for a user. Each token gets a random
+// jti, so tokens issued in the same second still differ, while sessionID
+// (the sid claim) groups the tokens reissued from one login; empty omits it.
+func GenerateToken(userID int, username, secret, sessionID string) (string, error) {
 	claims := Claims{
-		UserID:   userID,
-		Username: username,
+		UserID:    userID,
+		Username:  username,
+		SessionID: sessionID,
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(7 * 24 * time.Hour)), // 7 days
+			ID:        randomBase62(tokenIDLength),
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(TokenLifetime)),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 			NotBefore: jwt.NewNumericDate(time.Now()),
-			Issuer:    "realworld-api",
+			Issuer:    TokenClaims.Issuer,
 			Subject:   username,
 		},
 	}
+	if TokenClaims.Audience != "" {
+		claims.Audience = jwt.ClaimStrings{TokenClaims.Audience}
+	}
+
+	if TokenSigning.Algorithm == SigningRS256 {
+		return jwt.NewWithClaims(jwt.SigningMethodRS256, claims).SignedString(TokenSigning.PrivateKey)
+	}
 
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
 	return token.SignedString([]byte(secret))
 }
 
+// errTokenExpired rejects a token whose exp has passed
+var errTokenExpired = errors.New("token has expired")
+
 // ValidateToken validates a JWT token and returns the claims
 func ValidateToken(tokenString, secret string) (*Claims, error) {
 	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, func(token *jwt.Token) (interface{}, error) {
-		// Validate the signing method
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+		// Only the configured algorithm is accepted, so an RS256 deployment
+		// can't be fed HS256 tokens signed with its public key
+		if token.Method.Alg() != TokenSigning.Algorithm {
 			return nil, errors.New("invalid signing method")
 		}
+		if TokenSigning.Algorithm == SigningRS256 {
+			return &TokenSigning.PrivateKey.PublicKey, nil
+		}
 		return []byte(secret), nil
 	})
 
@@ -48,10 +149,54 @@ func ValidateToken(tokenString, secret string) (*Claims, error) {
 	if claims, ok := token.Claims.(*Claims); ok && token.Valid {
 		// Additional validation
 		if time.Now().After(claims.ExpiresAt.Time) {
-			return nil, errors.New("token has expired")
+			return nil, errTokenExpired
+		}
+		if err := TokenClaims.verify(claims); err != nil {
+			return nil, err
 		}
 		return claims, nil
 	}
 
 	return nil, errors.New("invalid token claims")
-}
\ No newline at end of file
+}
+
+// TokenFailureReason describes why ValidateToken rejected a token, for logging
+func TokenFailureReason(err error) string {
+	switch {
+	case errors.Is(err, jwt.ErrTokenMalformed):
+		return "malformed token"
+	case errors.Is(err, jwt.ErrTokenExpired), errors.Is(err, errTokenExpired):
+		return "expired token"
+	case errors.Is(err, jwt.ErrTokenSignatureInvalid), errors.Is(err, jwt.ErrTokenUnverifiable):
+		return "invalid signature"
+	default:
+		return "invalid token claims"
+	}
+}
+
+// verify checks a token's issuer and audience against the configuration
+func (c TokenClaimsConfig) verify(claims *Claims) error {
+	if claims.Issuer == "" {
+		if !c.AllowMissing {
+			return errors.New("token has no issuer")
+		}
+	} else if claims.Issuer != c.Issuer {
+		return errors.New("token issuer mismatch")
+	}
+
+	if c.Audience == "" {
+		return nil
+	}
+	if len(claims.Audience) == 0 {
+		if !c.AllowMissing {
+			return errors.New("token has no audience")
+		}
+		return nil
+	}
+	for _, aud := range claims.Audience {
+		if aud == c.Audience {
+			return nil
+		}
+	}
+	return errors.New("token audience mismatch")
+}
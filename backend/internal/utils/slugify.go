@@ -1,7 +1,9 @@
 package utils
 
 import (
+	"crypto/rand"
 	"fmt"
+	"math/big"
 	"regexp"
 	"strings"
 	"time"
@@ -41,8 +43,55 @@ func Slugify(s string) string {
 	return slug
 }
 
-// GenerateUniqueSlug creates a unique slug by appending a timestamp if needed
-func GenerateUniqueSlug(title string, checkExists func(string) bool) string {
+// reservedSlugs are path segments routed under /api/articles/ that an article
+// slug would be shadowed by
+var reservedSlugs = map[string]bool{
+	"count":            true,
+	"favorited-status": true,
+	"feed":             true,
+	"import":           true,
+}
+
+// IsReservedSlug reports whether slug collides with a route keyword
+func IsReservedSlug(slug string) bool {
+	return reservedSlugs[slug]
+}
+
+// SlugStrategy selects how article slugs are generated
+type SlugStrategy string
+
+const (
+	// SlugStrategyTitle derives slugs from the article title
+	SlugStrategyTitle SlugStrategy = "title"
+	// SlugStrategyRandom uses short opaque base62 ids that don't leak the title
+	SlugStrategyRandom SlugStrategy = "random"
+)
+
+const (
+	base62Alphabet   = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+	randomSlugLength = 10
+)
+
+// ParseSlugStrategy validates a configured slug strategy name
+func ParseSlugStrategy(s string) (SlugStrategy, error) {
+	switch SlugStrategy(s) {
+	case SlugStrategyTitle, SlugStrategyRandom:
+		return SlugStrategy(s), nil
+	default:
+		return "", fmt.Errorf("unknown slug strategy %q (expected title or random)", s)
+	}
+}
+
+// GenerateUniqueSlug creates a unique slug using the given strategy
+func GenerateUniqueSlug(title string, strategy SlugStrategy, checkExists func(string) bool) string {
+	if strategy == SlugStrategyRandom {
+		return generateUniqueRandomSlug(checkExists)
+	}
+	return generateUniqueTitleSlug(title, checkExists)
+}
+
+// generateUniqueTitleSlug creates a unique slug from the title by appending a timestamp if needed
+func generateUniqueTitleSlug(title string, checkExists func(string) bool) string {
 	baseSlug := Slugify(title)
 	if baseSlug == "" {
 		baseSlug = "article"
@@ -50,8 +99,8 @@ func GenerateUniqueSlug(title string, checkExists func(string) bool) string {
 
 	slug := baseSlug
 	
-	// Check if slug exists and modify if necessary
-	if checkExists(slug) {
+	// Check if slug exists or is reserved and modify if necessary
+	if checkExists(slug) || IsReservedSlug(slug) {
 		// Append timestamp to make it unique
 		timestamp := time.Now().Unix()
 		slug = fmt.Sprintf("%s-%d", baseSlug, timestamp)
@@ -63,4 +112,40 @@ func GenerateUniqueSlug(title string, checkExists func(string) bool) string {
 	}
 
 	return slug
-}
\ No newline at end of file
+}
+
+// generateUniqueRandomSlug creates a random base62 slug, probing until an unused one is found
+func generateUniqueRandomSlug(checkExists func(string) bool) string {
+	length := randomSlugLength
+	for attempt := 0; ; attempt++ {
+		slug := randomBase62(length)
+
+		// The articles schema requires at least one lowercase letter or digit
+		if !strings.ContainsAny(slug, base62Alphabet[:10]+base62Alphabet[36:]) {
+			continue
+		}
+
+		if !checkExists(slug) {
+			return slug
+		}
+
+		// Collisions this frequent mean the id space is crowded, so widen it
+		if attempt > 0 && attempt%3 == 0 {
+			length++
+		}
+	}
+}
+
+// randomBase62 returns a cryptographically random base62 string of length n
+func randomBase62(n int) string {
+	max := big.NewInt(int64(len(base62Alphabet)))
+	b := make([]byte, n)
+	for i := range b {
+		idx, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			panic("crypto/rand unavailable: " + err.Error())
+		}
+		b[i] = base62Alphabet[idx.Int64()]
+	}
+	return string(b)
+}